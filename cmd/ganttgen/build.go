@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/config"
+	"ganttgen/internal/csvinput"
+	"ganttgen/internal/model"
+	"ganttgen/internal/mspdi"
+	"ganttgen/internal/renderer"
+	"ganttgen/internal/scheduler"
+)
+
+// stdioPath selects stdin for the input and stdout for the output.
+const stdioPath = "-"
+
+// document is a scheduled project ready to be rendered in any output format.
+type document struct {
+	tasks             []model.Task
+	customColumns     []string
+	hasProgressColumn bool
+	cfg               config.Config
+	liveReloadURL     string
+	// source is the raw input CSV when there is exactly one, for the resolved CSV.
+	source   []byte
+	readOpts csvinput.Options
+}
+
+// outputFormat renders a document for one --format value.
+type outputFormat struct {
+	ext    string
+	render func(doc document) ([]byte, error)
+}
+
+var outputFormats = map[string]outputFormat{
+	"html":     {ext: ".html", render: renderHTML},
+	"svg":      {ext: ".svg", render: renderSVG},
+	"png":      {ext: ".png", render: renderPNG},
+	"pdf":      {ext: ".pdf", render: renderPDF},
+	"mermaid":  {ext: ".mmd", render: renderMermaid},
+	"plantuml": {ext: ".puml", render: renderPlantUML},
+	"mspdi":    {ext: ".xml", render: renderMSPDI},
+	"json":     {ext: ".json", render: renderJSON},
+	"csv":      {ext: ".csv", render: renderResolvedCSV},
+	"ics":      {ext: ".ics", render: renderICS},
+	"text":     {ext: ".txt", render: renderText},
+}
+
+func lookupFormat(name string) (outputFormat, error) {
+	format, ok := outputFormats[name]
+	if !ok {
+		return outputFormat{}, fmt.Errorf("unknown format %q (available: %s)", name, formatNames())
+	}
+	return format, nil
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(outputFormats))
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// renderOptions returns the renderer options for doc.
+func renderOptions(doc document) (renderer.Options, error) {
+	from, to, err := doc.cfg.Render.DateRange()
+	if err != nil {
+		return renderer.Options{}, err
+	}
+	return renderer.Options{
+		LiveReloadURL:     doc.liveReloadURL,
+		CustomColumns:     doc.customColumns,
+		HasProgressColumn: doc.hasProgressColumn,
+		Title:             doc.cfg.Render.Title,
+		CellWidth:         doc.cfg.Render.CellWidth,
+		HideNotes:         doc.cfg.Render.HideNotes,
+		Theme:             doc.cfg.Theme,
+		From:              from,
+		To:                to,
+		SideColumns:       doc.cfg.Render.SideColumns,
+		Scale:             doc.cfg.Render.Scale,
+		PageSize:          doc.cfg.Render.PageSize,
+		ICSEvents:         doc.cfg.Render.ICSEvents,
+	}, nil
+}
+
+func renderHTML(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	html, err := renderer.BuildHTMLWithOptions(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering HTML: %w", err)
+	}
+	return []byte(html), nil
+}
+
+func renderSVG(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	svg, err := renderer.BuildSVG(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering SVG: %w", err)
+	}
+	return []byte(svg), nil
+}
+
+func renderPNG(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := renderer.BuildPNG(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering PNG: %w", err)
+	}
+	return data, nil
+}
+
+func renderPDF(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := renderer.BuildPDF(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering PDF: %w", err)
+	}
+	return data, nil
+}
+
+func renderMermaid(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	text, err := renderer.BuildMermaid(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering Mermaid: %w", err)
+	}
+	return []byte(text), nil
+}
+
+func renderPlantUML(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	text, err := renderer.BuildPlantUML(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering PlantUML: %w", err)
+	}
+	return []byte(text), nil
+}
+
+func renderJSON(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := renderer.BuildJSON(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering JSON: %w", err)
+	}
+	return data, nil
+}
+
+func renderICS(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	text, err := renderer.BuildICS(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering iCalendar: %w", err)
+	}
+	return []byte(text), nil
+}
+
+func renderText(doc document) ([]byte, error) {
+	opts, err := renderOptions(doc)
+	if err != nil {
+		return nil, err
+	}
+	opts.TextWidth = doc.cfg.Render.TextWidth
+	if opts.TextWidth == 0 {
+		opts.TextWidth = terminalWidth()
+	}
+	if opts.TextColor, err = useColor(doc.cfg.Render.Color, doc.cfg.Output); err != nil {
+		return nil, err
+	}
+	text, err := renderer.BuildText(doc.tasks, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering text: %w", err)
+	}
+	return []byte(text), nil
+}
+
+func renderResolvedCSV(doc document) ([]byte, error) {
+	if doc.source == nil {
+		return nil, fmt.Errorf("the csv format needs exactly one input CSV file")
+	}
+	data, err := csvinput.Resolve(doc.source, doc.tasks, doc.readOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error writing resolved CSV: %w", err)
+	}
+	return data, nil
+}
+
+func renderMSPDI(doc document) ([]byte, error) {
+	data, err := mspdi.Write(doc.tasks, mspdi.Options{Title: doc.cfg.Render.Title})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting MS Project XML: %w", err)
+	}
+	return data, nil
+}
+
+// generate builds the chart and writes it to cfg.Output.
+func generate(inputs []string, cfg config.Config, liveReloadURL string) error {
+	data, err := build(inputs, cfg, liveReloadURL)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(cfg.Output, data); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// build reads, schedules and renders the inputs in cfg.Format.
+func build(inputs []string, cfg config.Config, liveReloadURL string) ([]byte, error) {
+	format, err := lookupFormat(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := load(inputs, cfg)
+	if err != nil {
+		return nil, err
+	}
+	doc.liveReloadURL = liveReloadURL
+	return format.render(doc)
+}
+
+// load applies the configuration, then reads and schedules the inputs.
+func load(inputs []string, cfg config.Config) (document, error) {
+	project, err := readProject(inputs)
+	if err != nil {
+		return document{}, err
+	}
+	if err := applyCalendar(cfg, project); err != nil {
+		return document{}, err
+	}
+
+	readOpts, err := readOptions(cfg)
+	if err != nil {
+		return document{}, err
+	}
+	// The columns and statuses files extend the values written in the config itself.
+	cancelled, completed := cfg.Status.Cancelled, cfg.Status.Completed
+	if cfg.ColumnsFile != "" {
+		mapping, err := csvinput.LoadColumnMapping(cfg.ColumnsFile)
+		if err != nil {
+			return document{}, fmt.Errorf("failed to load column mapping: %w", err)
+		}
+		readOpts.Columns = mergeColumns(readOpts.Columns, mapping.Columns)
+		cancelled = append(slices.Clone(cancelled), mapping.Status.Cancelled...)
+		completed = append(slices.Clone(completed), mapping.Status.Completed...)
+	}
+	model.SetStatusAliases(cancelled, completed)
+	statuses := cfg.Statuses
+	if cfg.StatusesFile != "" {
+		defs, err := model.ReadStatusesYAML(cfg.StatusesFile)
+		if err != nil {
+			return document{}, fmt.Errorf("failed to load statuses: %w", err)
+		}
+		statuses = append(slices.Clone(statuses), defs...)
+	}
+	if err := model.SetStatuses(statuses); err != nil {
+		return document{}, err
+	}
+
+	var (
+		tasks             []model.Task
+		customColumns     []string
+		hasProgressColumn bool
+		source            []byte
+	)
+	if project != nil {
+		tasks, hasProgressColumn = project.Tasks, project.HasProgress
+		if cfg.Render.Title == "" {
+			cfg.Render.Title = project.Title
+		}
+	} else {
+		source, tasks, customColumns, hasProgressColumn, err = readInput(inputs, readOpts)
+		if err != nil {
+			return document{}, fmt.Errorf("error reading CSV: %w", err)
+		}
+	}
+
+	scheduled, err := scheduler.Schedule(tasks)
+	if err != nil {
+		return document{}, fmt.Errorf("error scheduling tasks: %w", err)
+	}
+	if cfg.Timesheet != "" {
+		// The timesheet is usually another system's export, so its format is detected on its own.
+		entries, err := csvinput.ReadTimesheet(cfg.Timesheet, csvinput.Options{Anchors: readOpts.Anchors})
+		if err != nil {
+			return document{}, fmt.Errorf("error reading timesheet: %w", err)
+		}
+		if err := csvinput.ApplyTimesheet(scheduled, entries, cfg.HoursPerDay); err != nil {
+			return document{}, fmt.Errorf("error applying timesheet: %w", err)
+		}
+		for _, t := range scheduled {
+			if t.Effort != nil && t.Effort.ProgressEstimated {
+				hasProgressColumn = true
+			}
+		}
+	}
+	return document{
+		tasks:             scheduled,
+		customColumns:     customColumns,
+		hasProgressColumn: hasProgressColumn,
+		cfg:               cfg,
+		source:            source,
+		readOpts:          readOpts,
+	}, nil
+}
+
+// applyCalendar registers weekends, holidays and extra workdays from the config. The
+// calendar of an imported project adds its holidays and workdays, and its weekends apply
+// unless the config sets them.
+func applyCalendar(cfg config.Config, project *mspdi.Project) error {
+	calendar.SetAllWorkdays(cfg.Calendar.AllWorkdays)
+
+	var weekends []time.Weekday
+	for _, name := range cfg.Calendar.Weekends {
+		day, err := calendar.ParseWeekday(name)
+		if err != nil {
+			return fmt.Errorf("calendar weekends: %w", err)
+		}
+		weekends = append(weekends, day)
+	}
+	if weekends == nil && project != nil {
+		weekends = project.Weekends
+	}
+	calendar.SetWeekends(weekends)
+
+	workdays, err := calendar.ParseDates(cfg.Calendar.Workdays)
+	if err != nil {
+		return fmt.Errorf("calendar workdays: %w", err)
+	}
+	if project != nil {
+		workdays = append(workdays, project.Workdays...)
+	}
+	calendar.SetExtraWorkdays(workdays)
+
+	if cfg.Calendar.AllWorkdays {
+		calendar.SetHolidays(nil)
+		return nil
+	}
+	holidays, err := calendar.ParseDates(cfg.Calendar.Holidays)
+	if err != nil {
+		return fmt.Errorf("calendar holidays: %w", err)
+	}
+	for _, path := range cfg.Holidays {
+		dates, err := calendar.ReadHolidaysYAML(path)
+		if err != nil {
+			return fmt.Errorf("failed to load holidays: %w", err)
+		}
+		holidays = append(holidays, dates...)
+	}
+	if project != nil {
+		holidays = append(holidays, project.Holidays...)
+	}
+	calendar.SetHolidays(holidays)
+	return nil
+}
+
+func readOptions(cfg config.Config) (csvinput.Options, error) {
+	delimiter, err := csvinput.ParseDelimiter(cfg.Input.Delimiter)
+	if err != nil {
+		return csvinput.Options{}, err
+	}
+	return csvinput.Options{Anchors: cfg.Input.Anchors, Encoding: cfg.Input.Encoding, Delimiter: delimiter, Columns: cfg.Columns}, nil
+}
+
+// mergeColumns returns the inline column mapping of the config with the targets of the
+// columns file added; a target in both takes the headers of the file.
+func mergeColumns(inline, file map[string]csvinput.StringList) map[string]csvinput.StringList {
+	merged := make(map[string]csvinput.StringList, len(inline)+len(file))
+	maps.Copy(merged, inline)
+	maps.Copy(merged, file)
+	return merged
+}
+
+// isProjectXML reports whether path is an MS Project XML (MSPDI) file.
+func isProjectXML(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".xml")
+}
+
+// readProject reads an MS Project XML input, or returns nil when the inputs are CSV.
+func readProject(inputs []string) (*mspdi.Project, error) {
+	var xmlInputs int
+	for _, path := range inputs {
+		if isProjectXML(path) {
+			xmlInputs++
+		}
+	}
+	switch {
+	case xmlInputs == 0:
+		return nil, nil
+	case len(inputs) > 1:
+		return nil, fmt.Errorf("an MS Project XML file cannot be combined with other inputs")
+	}
+	project, err := mspdi.ReadFile(inputs[0])
+	if err != nil {
+		return nil, fmt.Errorf("error reading MS Project XML: %w", err)
+	}
+	return &project, nil
+}
+
+// readInput reads the input CSV files. A single input is also returned as it was read,
+// so that it can be written back with computed dates.
+func readInput(inputs []string, readOpts csvinput.Options) (source []byte, tasks []model.Task, customColumns []string, hasProgress bool, err error) {
+	if len(inputs) > 1 {
+		tasks, customColumns, hasProgress, err = csvinput.ReadFiles(inputs, readOpts)
+		return nil, tasks, customColumns, hasProgress, err
+	}
+	if inputs[0] == stdioPath {
+		if source, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, nil, nil, false, fmt.Errorf("read csv: %w", err)
+		}
+	} else if source, err = os.ReadFile(inputs[0]); err != nil {
+		return nil, nil, nil, false, fmt.Errorf("open csv: %w", err)
+	}
+	tasks, customColumns, hasProgress, err = csvinput.ReadFrom(bytes.NewReader(source), readOpts)
+	return source, tasks, customColumns, hasProgress, err
+}
+
+func writeOutput(output string, data []byte) error {
+	if output == stdioPath {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output, data, 0o644)
+}
+
+// reportGenerated prints the result line, keeping stdout clean when it carries the output itself.
+func reportGenerated(output string) {
+	if output == stdioPath {
+		fmt.Fprintln(os.Stderr, "generated to stdout")
+		return
+	}
+	fmt.Printf("generated %s\n", output)
+}
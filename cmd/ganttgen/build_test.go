@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"ganttgen/internal/config"
+	"ganttgen/internal/model"
+)
+
+func TestLoadAppliesConfigColumns(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "columns.yaml", "columns:\n  start: [Begin]\n")
+	configPath := writeTestFile(t, dir, "ganttgen.yaml", "columns_file: columns.yaml\ncolumns:\n  name: [Task Name]\n  start: [Kickoff]\n")
+	input := writeTestFile(t, dir, "plan.csv", "Task Name,Begin,end,duration,depends_on\n設計,2099-06-01,,2d,\n")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	doc, err := load([]string{input}, cfg)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(doc.tasks) != 1 || doc.tasks[0].Name != "設計" || doc.tasks[0].ComputedEnd.Format("2006-01-02") != "2099-06-02" {
+		t.Fatalf("unexpected tasks: %+v", doc.tasks)
+	}
+}
+
+func TestLoadCombinesStatusesOfConfigAndFiles(t *testing.T) {
+	t.Cleanup(func() {
+		model.SetStatusAliases(nil, nil)
+		_ = model.SetStatuses(nil)
+	})
+	dir := t.TempDir()
+	writeTestFile(t, dir, "columns.yaml", "status:\n  completed: [済]\n")
+	writeTestFile(t, dir, "statuses.yaml", "- {name: 保留, semantic: blocked}\n")
+	configPath := writeTestFile(t, dir, "ganttgen.yaml", "columns_file: columns.yaml\nstatuses_file: statuses.yaml\n"+
+		"status:\n  cancelled: [見送り]\nstatuses:\n  - {name: 進行中, semantic: in-progress}\n")
+	input := writeTestFile(t, dir, "plan.csv", "name,start,end,duration,depends_on,status\n"+
+		"A,2099-06-01,,1d,,見送り\nB,2099-06-01,,1d,,済\nC,2099-06-01,,1d,,進行中\nD,2099-06-01,,1d,,保留\n")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	doc, err := load([]string{input}, cfg)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !doc.tasks[0].IsCancelled() || !doc.tasks[1].IsCompleted() {
+		t.Fatalf("status aliases of the config and the columns file not both applied")
+	}
+	for _, name := range []string{"進行中", "保留"} {
+		if _, ok := model.LookupStatus(name); !ok {
+			t.Fatalf("status %q not registered", name)
+		}
+	}
+
+	writeTestFile(t, dir, "statuses.yaml", "- {name: 進行中}\n")
+	if _, err := load([]string{input}, cfg); err == nil {
+		t.Fatalf("expected an error for a status defined in both places")
+	}
+}
+
+func TestWatchedFilesIncludeTheTimesheet(t *testing.T) {
+	inputs := []string{"a.csv", "b.csv"}
+	if got := watchedFiles(inputs, config.Config{}); !slices.Equal(got, inputs) {
+		t.Fatalf("unexpected files without a timesheet: %q", got)
+	}
+	got := watchedFiles(inputs, config.Config{Timesheet: "hours.csv"})
+	if !slices.Equal(got, []string{"a.csv", "b.csv", "hours.csv"}) || len(inputs) != 2 {
+		t.Fatalf("unexpected files with a timesheet: %q (inputs %q)", got, inputs)
+	}
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"ganttgen/internal/config"
+	"ganttgen/internal/mspdi"
+	"ganttgen/internal/scaffold"
+)
+
+// command is one ganttgen subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	help    string
+	run     func(cmd *command, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name:    "generate",
+			args:    "[flags] <input.csv|-> [more.csv...]",
+			summary: "generate the chart once (the default command)",
+			help:    "Output goes to gantt.html next to the first input, or stdout for stdin input.",
+			run:     runGenerate,
+		},
+		{
+			name:    "watch",
+			args:    "[flags] <input.csv> [more.csv...]",
+			summary: "generate, then regenerate whenever an input changes",
+			run:     runWatch,
+		},
+		{
+			name:    "serve",
+			args:    "[flags] <input.csv> [more.csv...]",
+			summary: "serve the chart over HTTP and reload the browser on changes",
+			run:     runServe,
+		},
+		{
+			name:    "validate",
+			args:    "[flags] <input.csv|-> [more.csv...]",
+			summary: "check the inputs and schedule without writing output",
+			run:     runValidate,
+		},
+		{
+			name:    "export",
+			args:    "--format name [flags] <input.csv|-> [more.csv...]",
+			summary: "render the chart in another format (stdout unless -o is given)",
+			run:     runExport,
+		},
+		{
+			name:    "import",
+			args:    "[flags] <project.xml>",
+			summary: "convert an MS Project XML file into a CSV",
+			help:    "Output goes to the input name with .csv, or stdout with -o -.",
+			run:     runImport,
+		},
+		{
+			name:    "template",
+			args:    "[flags] <file.csv|->",
+			summary: "write a CSV template, optionally with example rows",
+			run:     runTemplate,
+		},
+		{
+			name:    "init",
+			args:    "[flags] [dir]",
+			summary: "create plan.csv, holidays.yaml and ganttgen.yaml for a new project",
+			help:    "Existing files are left untouched unless --force is given.",
+			run:     runInit,
+		},
+		{
+			name:    "config",
+			args:    "show [flags] [input.csv...]",
+			summary: "print the effective configuration",
+			run:     runConfig,
+		},
+		{
+			name:    "version",
+			args:    "",
+			summary: "print the version",
+			run:     runVersion,
+		},
+	}
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return nil
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return usageError{msg: fmt.Sprintf("unknown command %q", args[0])}
+	}
+	return cmd.run(cmd, []string{"-h"})
+}
+
+// generateFlags is the flag set shared by generate and config show.
+func generateFlags(cmd *command) (*flag.FlagSet, *cliFlags) {
+	fs, f := newFlagSet(cmd)
+	addOutputFlags(fs, f, "output file, - for stdout (default: gantt.<format> in the input CSV directory, stdout for stdin input)")
+	addRenderFlags(fs, f)
+	addInputFlags(fs, f)
+	fs.BoolVar(&f.watch, "watch", false, "watch input CSV and regenerate on changes")
+	addLiveReloadFlags(fs, f)
+	fs.StringVar(&f.templateCSVPath, "gen-template", "", "output an empty CSV template and exit (same as the template command)")
+	fs.BoolVar(&f.showVersion, "version", false, "print version and exit (same as the version command)")
+	return fs, f
+}
+
+func runGenerate(cmd *command, args []string) error {
+	fs, f := generateFlags(cmd)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if f.showVersion {
+		fmt.Println(versionString())
+		return nil
+	}
+	if f.templateCSVPath != "" {
+		return writeTemplate(f.templateCSVPath)
+	}
+	inputs, err := inputArgs(fs)
+	if err != nil {
+		return err
+	}
+	cfg, err := effectiveConfig(fs, f, inputs)
+	if err != nil {
+		return err
+	}
+	return generateAndWatch(inputs, cfg)
+}
+
+func runWatch(cmd *command, args []string) error {
+	fs, f := newFlagSet(cmd)
+	addOutputFlags(fs, f, "output file (default: gantt.<format> in the input CSV directory)")
+	addRenderFlags(fs, f)
+	addInputFlags(fs, f)
+	addLiveReloadFlags(fs, f)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	inputs, err := inputArgs(fs)
+	if err != nil {
+		return err
+	}
+	if inputs[0] == stdioPath {
+		return usageError{msg: "watch needs input files, not stdin"}
+	}
+	cfg, err := effectiveConfig(fs, f, inputs)
+	if err != nil {
+		return err
+	}
+	if cfg.Output == stdioPath {
+		return usageError{msg: "watch cannot write to stdout"}
+	}
+	cfg.Watch = true
+	return generateAndWatch(inputs, cfg)
+}
+
+// generateAndWatch writes the chart once and keeps regenerating when cfg.Watch is set.
+func generateAndWatch(inputs []string, cfg config.Config) error {
+	if (inputs[0] == stdioPath || cfg.Output == stdioPath) && (cfg.Watch || cfg.LiveReload.Enabled) {
+		fmt.Fprintln(os.Stderr, "warning: --watch/--livereload are ignored when reading from stdin or writing to stdout")
+		cfg.Watch = false
+		cfg.LiveReload.Enabled = false
+	}
+
+	var lr *liveReloader
+	liveReloadURL := ""
+	if cfg.LiveReload.Enabled {
+		var err error
+		lr, liveReloadURL, err = startLiveReload(cfg.LiveReload.Port)
+		if err != nil {
+			return fmt.Errorf("failed to start livereload: %w", err)
+		}
+		cfg.Watch = true // livereload implies watch for change events
+	}
+
+	if err := generate(inputs, cfg, liveReloadURL); err != nil {
+		return err
+	}
+	reportGenerated(cfg.Output)
+
+	if !cfg.Watch {
+		return nil
+	}
+	err := watchInputs(watchedFiles(inputs, cfg), func() error {
+		if err := generate(inputs, cfg, liveReloadURL); err != nil {
+			return err
+		}
+		reportGenerated(cfg.Output)
+		return nil
+	}, lr)
+	if err != nil {
+		return fmt.Errorf("watch error: %w", err)
+	}
+	return nil
+}
+
+func runServe(cmd *command, args []string) error {
+	fs, f := newFlagSet(cmd)
+	addRenderFlags(fs, f)
+	addInputFlags(fs, f)
+	fs.StringVar(&f.addr, "addr", "127.0.0.1:8080", "address to listen on")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	inputs, err := inputArgs(fs)
+	if err != nil {
+		return err
+	}
+	if inputs[0] == stdioPath {
+		return usageError{msg: "serve needs input files, not stdin"}
+	}
+	cfg, err := effectiveConfig(fs, f, inputs)
+	if err != nil {
+		return err
+	}
+	cfg.Format = "html"
+
+	var (
+		mu   sync.RWMutex
+		page []byte
+	)
+	rebuild := func() error {
+		data, err := build(inputs, cfg, "/livereload")
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		page = data
+		mu.Unlock()
+		return nil
+	}
+	if err := rebuild(); err != nil {
+		return err
+	}
+
+	lr := newLiveReloader()
+	mux := http.NewServeMux()
+	mux.Handle("/livereload", lr)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.RLock()
+		defer mu.RUnlock()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	ln, err := net.Listen("tcp", f.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		}
+	}()
+	defer server.Close()
+
+	fmt.Printf("serving on http://%s/\n", ln.Addr())
+	if err := watchInputs(watchedFiles(inputs, cfg), rebuild, lr); err != nil {
+		return fmt.Errorf("watch error: %w", err)
+	}
+	return nil
+}
+
+func runValidate(cmd *command, args []string) error {
+	fs, f := newFlagSet(cmd)
+	addInputFlags(fs, f)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	inputs, err := inputArgs(fs)
+	if err != nil {
+		return err
+	}
+	cfg, err := effectiveConfig(fs, f, inputs)
+	if err != nil {
+		return err
+	}
+	doc, err := load(inputs, cfg)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, t := range doc.tasks {
+		if !t.IsHeading && !t.DisplayOnly {
+			count++
+		}
+	}
+	fmt.Printf("ok: %d tasks\n", count)
+	return nil
+}
+
+func runExport(cmd *command, args []string) error {
+	fs, f := newFlagSet(cmd)
+	addOutputFlags(fs, f, "output file, - for stdout (default: stdout)")
+	addRenderFlags(fs, f)
+	addInputFlags(fs, f)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	inputs, err := inputArgs(fs)
+	if err != nil {
+		return err
+	}
+	output := stdioPath
+	fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "o" || fl.Name == "output" {
+			output = f.output
+		}
+	})
+	cfg, err := effectiveConfig(fs, f, inputs)
+	if err != nil {
+		return err
+	}
+	cfg.Output = output
+	if err := generate(inputs, cfg, ""); err != nil {
+		return err
+	}
+	if output != stdioPath {
+		reportGenerated(output)
+	}
+	return nil
+}
+
+func runImport(cmd *command, args []string) error {
+	fs, _ := newFlagSet(cmd)
+	var opts scaffold.Options
+	var output, configOutput string
+	fs.StringVar(&opts.Lang, "lang", "ja", "header language: ja or en")
+	fs.StringVar(&output, "o", "", "output CSV file, - for stdout (default: the input name with .csv)")
+	fs.StringVar(&output, "output", "", "output CSV file, - for stdout (default: the input name with .csv)")
+	fs.StringVar(&configOutput, "config-output", "", "also write the title and calendar of the project to this config file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError{}
+	}
+	path := fs.Arg(0)
+	project, err := mspdi.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading MS Project XML: %w", err)
+	}
+	data, err := scaffold.TasksCSV(project.Tasks, opts)
+	if err != nil {
+		return usageError{msg: err.Error()}
+	}
+	if output == "" {
+		output = strings.TrimSuffix(path, filepath.Ext(path)) + ".csv"
+	}
+	if err := writeOutput(output, data); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	reportGenerated(output)
+	if configOutput == "" {
+		return nil
+	}
+	data, err = projectConfig(project).YAML()
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.WriteFile(configOutput, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", configOutput, err)
+	}
+	reportGenerated(configOutput)
+	return nil
+}
+
+// projectConfig returns a configuration with the title and calendar of an imported
+// project, so that the CSV schedules the same way without the XML file.
+func projectConfig(project mspdi.Project) config.Config {
+	cfg := config.Config{LiveReload: config.LiveReload{Port: config.DefaultLiveReloadPort}}
+	cfg.Render.Title = project.Title
+	if project.Weekends != nil {
+		cfg.Calendar.AllWorkdays = len(project.Weekends) == 0
+		for _, d := range project.Weekends {
+			cfg.Calendar.Weekends = append(cfg.Calendar.Weekends, strings.ToLower(d.String()))
+		}
+	}
+	for _, d := range project.Holidays {
+		cfg.Calendar.Holidays = append(cfg.Calendar.Holidays, d.Format("2006-01-02"))
+	}
+	for _, d := range project.Workdays {
+		cfg.Calendar.Workdays = append(cfg.Calendar.Workdays, d.Format("2006-01-02"))
+	}
+	return cfg
+}
+
+// addTemplateFlags registers the flags shared by template and init.
+func addTemplateFlags(fs *flag.FlagSet, opts *scaffold.Options, examplesDefault bool) {
+	fs.StringVar(&opts.Lang, "lang", "ja", "header and comment language: ja or en")
+	fs.BoolVar(&opts.Examples, "examples", examplesDefault, "add example rows (sections, dependencies, actuals, progress)")
+	fs.Var((*columnList)(&opts.ExtraColumns), "extra-columns", "comma-separated custom columns to add, e.g. assignee,team")
+}
+
+func runTemplate(cmd *command, args []string) error {
+	fs, _ := newFlagSet(cmd)
+	var opts scaffold.Options
+	addTemplateFlags(fs, &opts, false)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError{}
+	}
+	data, err := scaffold.CSV(opts)
+	if err != nil {
+		return usageError{msg: err.Error()}
+	}
+	path := fs.Arg(0)
+	if err := writeOutput(path, data); err != nil {
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+	if path != stdioPath {
+		fmt.Printf("generated %s\n", path)
+	}
+	return nil
+}
+
+func runInit(cmd *command, args []string) error {
+	fs, _ := newFlagSet(cmd)
+	var opts scaffold.Options
+	addTemplateFlags(fs, &opts, true)
+	force := fs.Bool("force", false, "overwrite existing files")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return usageError{}
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+	files, err := scaffold.Files(opts)
+	if err != nil {
+		return usageError{msg: err.Error()}
+	}
+	names := []string{scaffold.CSVFileName, scaffold.HolidaysFileName, scaffold.ConfigFileName}
+	if !*force {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", filepath.Join(dir, name))
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		fmt.Printf("generated %s\n", path)
+	}
+	return nil
+}
+
+// writeTemplate writes the header-only template for the legacy --gen-template flag.
+func writeTemplate(path string) error {
+	data, err := scaffold.CSV(scaffold.Options{})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write empty CSV: %w", err)
+	}
+	fmt.Printf("generated %s\n", path)
+	return nil
+}
+
+// columnList is a comma-separated flag value; repeating the flag appends.
+type columnList []string
+
+func (c *columnList) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *columnList) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*c = append(*c, name)
+		}
+	}
+	return nil
+}
+
+// runConfig implements "ganttgen config show": it prints the configuration that
+// generation would use after applying the config file and command-line flags.
+func runConfig(cmd *command, args []string) error {
+	fs, f := generateFlags(cmd)
+	if len(args) > 0 && args[0] != "show" && args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+		return usageError{msg: fmt.Sprintf("unknown config subcommand %q", args[0])}
+	}
+	if len(args) > 0 && args[0] == "show" {
+		args = args[1:]
+	} else if len(args) == 0 {
+		fs.Usage()
+		return usageError{}
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := effectiveConfig(fs, f, fs.Args())
+	if err != nil {
+		return err
+	}
+	data, err := cfg.YAML()
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	source := "(defaults)"
+	if cfg.Path != "" {
+		source = cfg.Path
+	}
+	fmt.Printf("# config: %s\n%s", source, data)
+	return nil
+}
+
+func runVersion(cmd *command, args []string) error {
+	fs, _ := newFlagSet(cmd)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	fmt.Println(versionString())
+	return nil
+}
@@ -1,255 +1,330 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"sync"
-	"syscall"
-	"time"
+	"strings"
 
-	"ganttgen/internal/calendar"
+	"ganttgen/internal/config"
 	"ganttgen/internal/csvinput"
 	"ganttgen/internal/renderer"
-	"ganttgen/internal/scheduler"
 )
 
 var version = "dev"
 
-const sampleCSVHeader = "タスク名,状態,進捗,開始,終了,期間,依存,実績開始,実績終了,実績期間,備考\n"
+// Exit codes shared by every command.
+const (
+	exitOK      = 0
+	exitFailure = 1 // invalid input, scheduling or I/O errors
+	exitUsage   = 2 // unknown command, bad flags or missing arguments
+)
 
-func main() {
-	var output string
-	var holidaysPath string
-	var allWorkdays bool
-	var templateCSVPath string
-	var watch bool
-	var liveReload bool
-	var liveReloadPort int
-	var showVersion bool
-	flag.StringVar(&output, "o", "", "output HTML file (default: gantt.html in the input CSV directory)")
-	flag.StringVar(&output, "output", "", "output HTML file (default: gantt.html in the input CSV directory)")
-	flag.StringVar(&holidaysPath, "holidays", "", "optional YAML file listing YYYY-MM-DD holidays")
-	flag.BoolVar(&allWorkdays, "all-workdays", false, "treat weekends and holidays as workdays")
-	flag.StringVar(&templateCSVPath, "gen-template", "", "output an empty CSV template and exit")
-	flag.BoolVar(&watch, "watch", false, "watch input CSV and regenerate on changes")
-	flag.BoolVar(&liveReload, "livereload", false, "enable livereload server and inject client script")
-	flag.IntVar(&liveReloadPort, "livereload-port", 35729, "port for livereload server (default 35729)")
-	flag.BoolVar(&showVersion, "version", false, "print version and exit")
-	flag.Parse()
-
-	args := flag.Args()
-	if showVersion {
-		fmt.Println(versionString())
-		return
-	}
-	if templateCSVPath != "" {
-		if err := writeEmptyCSV(templateCSVPath); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to write empty CSV: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("generated %s\n", templateCSVPath)
-		return
-	}
-	if len(args) != 1 {
-		fmt.Fprintf(os.Stderr, "Usage: ganttgen [--output file] [--holidays file] [--all-workdays] [--gen-template file] [--watch] [--livereload] [--livereload-port port] [--version] <input.csv>\n")
-		os.Exit(1)
-	}
-	input := args[0]
-	if output == "" {
-		output = filepath.Join(filepath.Dir(input), "gantt.html")
-	}
-
-	var lr *liveReloader
-	liveReloadURL := ""
-	if liveReload {
-		var err error
-		lr, liveReloadURL, err = startLiveReload(liveReloadPort)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to start livereload: %v\n", err)
-			os.Exit(1)
-		}
-		watch = true // livereload implies watch for change events
-	}
+// errHelp reports that help was requested and printed.
+var errHelp = errors.New("help requested")
 
-	if err := generate(input, output, holidaysPath, allWorkdays, liveReloadURL); err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
-	}
+// usageError is a command-line mistake; an empty message means the usage was already printed.
+type usageError struct {
+	msg string
+}
 
-	fmt.Printf("generated %s\n", output)
+func (e usageError) Error() string {
+	return e.msg
+}
 
-	if watch {
-		if err := watchAndGenerate(input, output, holidaysPath, allWorkdays, liveReloadURL, lr); err != nil {
-			fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
-			os.Exit(1)
-		}
-	}
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
 
-func generate(input, output, holidaysPath string, allWorkdays bool, liveReloadURL string) error {
-	calendar.SetAllWorkdays(allWorkdays)
-	if allWorkdays {
-		calendar.SetHolidays(nil)
-	} else if holidaysPath != "" {
-		if err := calendar.LoadHolidaysYAML(holidaysPath); err != nil {
-			return fmt.Errorf("failed to load holidays: %w", err)
+func run(args []string) int {
+	err := dispatch(args)
+	var usageErr usageError
+	switch {
+	case err == nil, errors.Is(err, errHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		if usageErr.msg != "" {
+			fmt.Fprintln(os.Stderr, usageErr.msg)
+			fmt.Fprintln(os.Stderr, `Run "ganttgen help" for usage.`)
 		}
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitFailure
 	}
+}
 
-	tasks, customColumns, hasProgressColumn, err := csvinput.Read(input)
-	if err != nil {
-		return fmt.Errorf("error reading CSV: %w", err)
+// dispatch runs the named command; anything else is handed to "generate" so that
+// "ganttgen file.csv" keeps working.
+func dispatch(args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return usageError{}
 	}
-
-	scheduled, err := scheduler.Schedule(tasks)
-	if err != nil {
-		return fmt.Errorf("error scheduling tasks: %w", err)
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		return runHelp(args[1:])
 	}
-
-	html, err := renderer.BuildHTML(scheduled, liveReloadURL, customColumns, hasProgressColumn)
-	if err != nil {
-		return fmt.Errorf("error rendering HTML: %w", err)
+	if cmd := findCommand(args[0]); cmd != nil {
+		return cmd.run(cmd, args[1:])
 	}
+	cmd := findCommand("generate")
+	return cmd.run(cmd, args)
+}
 
-	if err := os.WriteFile(output, []byte(html), 0o644); err != nil {
-		return fmt.Errorf("write output: %w", err)
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ganttgen <command> [flags] [args]")
+	fmt.Fprintln(w, "       ganttgen [flags] <input.csv|-> [more.csv...]   (same as generate)")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
 	}
-	return nil
+	fmt.Fprintln(w, "\nRun \"ganttgen help <command>\" or \"ganttgen <command> -h\" for the flags of a command.")
+	fmt.Fprintln(w, "Exit status: 0 on success, 1 on invalid input or I/O errors, 2 on usage errors.")
 }
 
-func watchAndGenerate(input, output, holidaysPath string, allWorkdays bool, liveReloadURL string, lr *liveReloader) error {
-	info, err := os.Stat(input)
-	if err != nil {
-		return fmt.Errorf("stat input: %w", err)
+// cliFlags holds the command-line options; values set explicitly override the config file.
+type cliFlags struct {
+	configPath      string
+	format          string
+	output          string
+	holidaysPath    string
+	allWorkdays     bool
+	templateCSVPath string
+	watch           bool
+	liveReload      bool
+	liveReloadPort  int
+	showVersion     bool
+	encoding        string
+	delimiterName   string
+	columnsPath     string
+	statusesPath    string
+	timesheetPath   string
+	hoursPerDay     float64
+	addr            string
+	from            string
+	to              string
+	sideColumns     string
+	scale           float64
+	pageSize        string
+	icsEvents       string
+	textWidth       int
+	color           string
+	anchors         anchorFlags
+}
+
+// newFlagSet creates an empty flag set for cmd whose usage prints the command help.
+func newFlagSet(cmd *command) (*flag.FlagSet, *cliFlags) {
+	f := &cliFlags{anchors: anchorFlags{}}
+	fs := flag.NewFlagSet("ganttgen "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: ganttgen %s %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+		if cmd.help != "" {
+			fmt.Fprintf(out, "\n%s\n", cmd.help)
+		}
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(out, "\nFlags:")
+			fs.PrintDefaults()
+		}
 	}
-	lastMod := info.ModTime()
-	lastSize := info.Size()
+	return fs, f
+}
 
-	fmt.Printf("watching %s for changes (Ctrl+C to stop)...\n", input)
+// addInputFlags registers the flags that control reading and scheduling.
+func addInputFlags(fs *flag.FlagSet, f *cliFlags) {
+	fs.StringVar(&f.configPath, "config", "", "project config file (default: ganttgen.yaml next to the first input CSV)")
+	fs.StringVar(&f.holidaysPath, "holidays", "", "optional YAML file listing YYYY-MM-DD holidays")
+	fs.BoolVar(&f.allWorkdays, "all-workdays", false, "treat weekends and holidays as workdays")
+	fs.StringVar(&f.encoding, "encoding", "auto", "input CSV encoding: auto, utf-8, shift_jis, euc-jp, utf-16, utf-16le or utf-16be")
+	fs.StringVar(&f.delimiterName, "delimiter", "auto", "input field delimiter: auto, ',', ';', tab or any single character")
+	fs.StringVar(&f.columnsPath, "columns", "", "optional YAML file mapping header names to columns and extra cancelled/completed status values")
+	fs.StringVar(&f.statusesPath, "statuses", "", "optional YAML file defining status names, aliases, colors and semantics")
+	fs.StringVar(&f.timesheetPath, "timesheet", "", "optional timesheet CSV (date, task, person, hours) to derive actuals, effort and progress")
+	fs.Float64Var(&f.hoursPerDay, "hours-per-day", csvinput.DefaultHoursPerDay, "working hours in a planned workday, used to compare timesheet hours with the plan")
+	fs.Var(f.anchors, "anchor", "date anchor for relative dates: DATE sets the project anchor, NAME=DATE defines @NAME (repeatable)")
+}
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	defer signal.Stop(sigCh)
+// addOutputFlags registers --format and -o/--output.
+func addOutputFlags(fs *flag.FlagSet, f *cliFlags, outputHelp string) {
+	fs.StringVar(&f.format, "format", "html", "output format: "+formatNames())
+	fs.StringVar(&f.output, "o", "", outputHelp)
+	fs.StringVar(&f.output, "output", "", outputHelp)
+}
 
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+// addRenderFlags registers the flags that select what the chart shows.
+func addRenderFlags(fs *flag.FlagSet, f *cliFlags) {
+	fs.StringVar(&f.from, "from", "", "first date shown, YYYY-MM-DD (default: fit to the tasks)")
+	fs.StringVar(&f.to, "to", "", "last date shown, YYYY-MM-DD (default: fit to the tasks)")
+	fs.StringVar(&f.sideColumns, "side-columns", "", "comma-separated columns left of the timeline in image formats: name, status, progress, notes or a custom column")
+	fs.Float64Var(&f.scale, "scale", renderer.DefaultScale, "pixel ratio of PNG output (e.g. 2 for high-DPI displays)")
+	fs.StringVar(&f.pageSize, "page-size", renderer.DefaultPageSize, "landscape paper size of PDF output: a3 or a4")
+	fs.StringVar(&f.icsEvents, "ics-events", renderer.ICSAllTasks, "events of ics output: all (every task) or milestones (milestones and fixed end dates)")
+	fs.IntVar(&f.textWidth, "text-width", 0, "width of text output in columns (default: the terminal width, or 80)")
+	fs.StringVar(&f.color, "color", colorAuto, "ANSI colors in text output: auto (when writing to a terminal), always or never")
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			info, err := os.Stat(input)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "watch: stat failed: %v\n", err)
-				continue
-			}
-			if info.ModTime() == lastMod && info.Size() == lastSize {
-				continue
-			}
+// addLiveReloadFlags registers the livereload server flags.
+func addLiveReloadFlags(fs *flag.FlagSet, f *cliFlags) {
+	fs.BoolVar(&f.liveReload, "livereload", false, "enable livereload server and inject client script")
+	fs.IntVar(&f.liveReloadPort, "livereload-port", config.DefaultLiveReloadPort, "port for livereload server")
+}
 
-			lastMod = info.ModTime()
-			lastSize = info.Size()
+// parseFlags parses args; the flag package has already printed any error or help text.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errHelp
+		}
+		return usageError{}
+	}
+	return nil
+}
 
-			fmt.Printf("[%s] change detected, regenerating...\n", time.Now().Format("15:04:05"))
-			if err := generate(input, output, holidaysPath, allWorkdays, liveReloadURL); err != nil {
-				fmt.Fprintf(os.Stderr, "regenerate failed: %v\n", err)
-				continue
-			}
-			fmt.Printf("generated %s\n", output)
-			if lr != nil {
-				lr.Reload()
+// inputArgs returns the positional input files of fs.
+func inputArgs(fs *flag.FlagSet) ([]string, error) {
+	inputs := fs.Args()
+	if len(inputs) < 1 {
+		fs.Usage()
+		return nil, usageError{}
+	}
+	if len(inputs) > 1 {
+		for _, in := range inputs {
+			if in == stdioPath {
+				return nil, usageError{msg: "stdin input (-) cannot be combined with other input files"}
 			}
-		case <-sigCh:
-			fmt.Println("stop watching")
-			return nil
 		}
 	}
+	return inputs, nil
 }
 
-type liveReloader struct {
-	mu      sync.Mutex
-	clients map[chan struct{}]struct{}
+// effectiveConfig loads the config file (--config, or ganttgen.yaml next to the first
+// input) and overrides it with the flags given on the command line.
+func effectiveConfig(fs *flag.FlagSet, f *cliFlags, inputs []string) (config.Config, error) {
+	dir := "."
+	if len(inputs) > 0 && inputs[0] != stdioPath {
+		dir = filepath.Dir(inputs[0])
+	}
+	cfg, err := config.LoadOrDiscover(f.configPath, dir)
+	if err != nil {
+		return config.Config{}, err
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "format":
+			cfg.Format = f.format
+		case "o", "output":
+			cfg.Output = f.output
+		case "holidays":
+			cfg.Holidays = csvinput.StringList{f.holidaysPath}
+		case "all-workdays":
+			cfg.Calendar.AllWorkdays = f.allWorkdays
+		case "watch":
+			cfg.Watch = f.watch
+		case "livereload":
+			cfg.LiveReload.Enabled = f.liveReload
+		case "livereload-port":
+			cfg.LiveReload.Port = f.liveReloadPort
+		case "encoding":
+			cfg.Input.Encoding = f.encoding
+		case "delimiter":
+			cfg.Input.Delimiter = f.delimiterName
+		case "columns":
+			cfg.ColumnsFile = f.columnsPath
+		case "statuses":
+			cfg.StatusesFile = f.statusesPath
+		case "timesheet":
+			cfg.Timesheet = f.timesheetPath
+		case "hours-per-day":
+			cfg.HoursPerDay = f.hoursPerDay
+		case "from":
+			cfg.Render.From = f.from
+		case "to":
+			cfg.Render.To = f.to
+		case "side-columns":
+			cfg.Render.SideColumns = splitFlagList(f.sideColumns)
+		case "scale":
+			cfg.Render.Scale = f.scale
+		case "page-size":
+			cfg.Render.PageSize = f.pageSize
+		case "ics-events":
+			cfg.Render.ICSEvents = f.icsEvents
+		case "text-width":
+			cfg.Render.TextWidth = f.textWidth
+		case "color":
+			cfg.Render.Color = f.color
+		case "anchor":
+			if cfg.Input.Anchors == nil {
+				cfg.Input.Anchors = map[string]string{}
+			}
+			for name, expr := range f.anchors {
+				cfg.Input.Anchors[name] = expr
+			}
+		}
+	})
+	if _, _, err := cfg.Render.DateRange(); err != nil {
+		return config.Config{}, usageError{msg: err.Error()}
+	}
+	format, err := lookupFormat(cfg.Format)
+	if err != nil {
+		return config.Config{}, usageError{msg: err.Error()}
+	}
+	if cfg.Output == "" && len(inputs) > 0 {
+		if inputs[0] == stdioPath {
+			cfg.Output = stdioPath
+		} else {
+			cfg.Output = filepath.Join(filepath.Dir(inputs[0]), "gantt"+format.ext)
+		}
+	}
+	for _, input := range inputs {
+		if cfg.Output != stdioPath && filepath.Clean(cfg.Output) == filepath.Clean(input) {
+			return config.Config{}, usageError{msg: fmt.Sprintf("output %s would overwrite an input file", cfg.Output)}
+		}
+	}
+	return cfg, nil
 }
 
-func startLiveReload(port int) (*liveReloader, string, error) {
-	lr := &liveReloader{
-		clients: make(map[chan struct{}]struct{}),
+// splitFlagList splits a comma-separated flag value, dropping empty items.
+func splitFlagList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	mux := http.NewServeMux()
-	mux.Handle("/livereload", lr)
+// anchorFlags collects --anchor values; a value without "=" sets the project anchor.
+type anchorFlags map[string]string
 
-	addr := fmt.Sprintf("127.0.0.1:%d", port)
-	server := &http.Server{
-		Addr:    addr,
-		Handler: mux,
+func (a anchorFlags) String() string {
+	parts := make([]string, 0, len(a))
+	for name, expr := range a {
+		parts = append(parts, name+"="+expr)
 	}
-
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Fprintf(os.Stderr, "livereload server error: %v\n", err)
-		}
-	}()
-
-	url := fmt.Sprintf("http://%s/livereload", addr)
-	fmt.Printf("livereload server listening on %s\n", url)
-	return lr, url, nil
+	return strings.Join(parts, ",")
 }
 
-func (lr *liveReloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	flusher, ok := w.(http.Flusher)
+func (a anchorFlags) Set(value string) error {
+	name, expr, ok := strings.Cut(value, "=")
 	if !ok {
-		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
-	client := make(chan struct{}, 1)
-	lr.mu.Lock()
-	lr.clients[client] = struct{}{}
-	lr.mu.Unlock()
-
-	// Send initial ping to establish connection
-	fmt.Fprintf(w, "data: ping\n\n")
-	flusher.Flush()
-
-	ctx := r.Context()
-	for {
-		select {
-		case <-ctx.Done():
-			lr.mu.Lock()
-			delete(lr.clients, client)
-			lr.mu.Unlock()
-			return
-		case <-client:
-			fmt.Fprintf(w, "data: reload\n\n")
-			flusher.Flush()
-		}
+		name, expr = "project", value
 	}
-}
-
-func (lr *liveReloader) Reload() {
-	lr.mu.Lock()
-	defer lr.mu.Unlock()
-	for ch := range lr.clients {
-		select {
-		case ch <- struct{}{}:
-		default:
-		}
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	expr = strings.TrimSpace(expr)
+	if name == "" || expr == "" {
+		return fmt.Errorf("anchor must be DATE or NAME=DATE")
 	}
+	a[name] = expr
+	return nil
 }
 
 func versionString() string {
 	return fmt.Sprintf("%s", version)
 }
-
-func writeEmptyCSV(path string) error {
-	return os.WriteFile(path, []byte(sampleCSVHeader), 0o644)
-}
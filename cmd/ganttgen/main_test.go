@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ganttgen/internal/config"
+)
+
+// writeTestFile writes content to name in dir and returns its path.
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+// quiet discards what fn writes to stdout and stderr.
+func quiet(t *testing.T, fn func()) {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = devNull, devNull
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	fn()
+}
+
+func TestRunDispatchesCommandsWithExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestFile(t, dir, "plan.csv", "name,start,end,duration,depends_on\n設計,2099-06-01,,2d,\n")
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no arguments", nil, exitUsage},
+		{"help", []string{"help"}, exitOK},
+		{"help of an unknown command", []string{"help", "nosuch"}, exitUsage},
+		{"version", []string{"version"}, exitOK},
+		{"validate", []string{"validate", input}, exitOK},
+		{"unknown flag", []string{"validate", "--nosuch", input}, exitUsage},
+		{"missing input", []string{"generate"}, exitUsage},
+		{"unknown format", []string{"export", "--format", "nosuch", input}, exitUsage},
+		{"output over the input", []string{"export", "--format", "csv", "-o", input, input}, exitUsage},
+		{"legacy form", []string{"-o", filepath.Join(dir, "legacy.html"), input}, exitOK},
+		{"legacy form with a missing file", []string{filepath.Join(dir, "missing.csv")}, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			quiet(t, func() { got = run(tt.args) })
+			if got != tt.want {
+				t.Fatalf("run(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(dir, "legacy.html")); err != nil {
+		t.Fatalf("legacy form did not generate: %v", err)
+	}
+}
+
+func TestEffectiveConfigPrefersExplicitFlags(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "ganttgen.yaml", "input:\n  encoding: shift_jis\nrender:\n  title: Config title\nlivereload:\n  port: 40000\n")
+	input := writeTestFile(t, dir, "plan.csv", "name,start,end,duration,depends_on\n")
+	tests := []struct {
+		name     string
+		args     []string
+		encoding string
+		output   string
+		port     int
+	}{
+		{"config values", nil, "shift_jis", filepath.Join(dir, "gantt.html"), 40000},
+		{"encoding flag", []string{"--encoding", "utf-8"}, "utf-8", filepath.Join(dir, "gantt.html"), 40000},
+		{"flag equal to its default", []string{"--livereload-port", "35729"}, "shift_jis", filepath.Join(dir, "gantt.html"), config.DefaultLiveReloadPort},
+		{"output flag", []string{"-o", "-"}, "shift_jis", "-", 40000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, f := generateFlags(findCommand("generate"))
+			if err := parseFlags(fs, append(tt.args, input)); err != nil {
+				t.Fatalf("parse flags: %v", err)
+			}
+			cfg, err := effectiveConfig(fs, f, fs.Args())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Input.Encoding != tt.encoding || cfg.Output != tt.output || cfg.LiveReload.Port != tt.port || cfg.Render.Title != "Config title" {
+				t.Fatalf("got encoding %q, output %q, port %d, title %q", cfg.Input.Encoding, cfg.Output, cfg.LiveReload.Port, cfg.Render.Title)
+			}
+		})
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	tests := []struct {
+		mode, output string
+		want         bool
+		wantErr      bool
+	}{
+		{mode: "", output: "plan.txt", want: false},
+		{mode: colorAuto, output: "plan.txt", want: false},
+		{mode: colorAuto, output: stdioPath, want: false}, // NO_COLOR is set below
+		{mode: colorAlways, output: "plan.txt", want: true},
+		{mode: "ALWAYS", output: stdioPath, want: true},
+		{mode: colorNever, output: stdioPath, want: false},
+		{mode: "rainbow", output: stdioPath, wantErr: true},
+	}
+	t.Setenv("NO_COLOR", "1")
+	for _, tt := range tests {
+		got, err := useColor(tt.mode, tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("useColor(%q, %q) = %v, %v; want %v (error %v)", tt.mode, tt.output, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Values of --color.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// useColor reports whether text output gets ANSI colors: always, never, or in auto mode
+// when it goes to a terminal and NO_COLOR is not set.
+func useColor(mode, output string) (bool, error) {
+	switch strings.ToLower(mode) {
+	case "", colorAuto:
+		return output == stdioPath && isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb", nil
+	case colorAlways:
+		return true, nil
+	case colorNever:
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown color mode %q (use %s, %s or %s)", mode, colorAuto, colorAlways, colorNever)
+}
+
+// terminalWidth returns the width of the terminal in columns from $COLUMNS or stdout, or
+// zero when it is unknown.
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if isTerminal(os.Stdout) {
+		return ttyColumns(os.Stdout)
+	}
+	return 0
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// ttyColumns returns zero: the terminal width is only known from $COLUMNS here.
+func ttyColumns(*os.File) int {
+	return 0
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"ganttgen/internal/config"
+)
+
+// watchedFiles lists the files whose changes rebuild the chart: the inputs and the
+// timesheet.
+func watchedFiles(inputs []string, cfg config.Config) []string {
+	if cfg.Timesheet == "" {
+		return inputs
+	}
+	return append(slices.Clone(inputs), cfg.Timesheet)
+}
+
+// watchInputs polls the inputs every second and calls regenerate after a change,
+// notifying livereload clients on success. It returns on SIGINT/SIGTERM.
+func watchInputs(inputs []string, regenerate func() error, lr *liveReloader) error {
+	type fileState struct {
+		mod  time.Time
+		size int64
+	}
+	states := make(map[string]fileState, len(inputs))
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			return fmt.Errorf("stat input: %w", err)
+		}
+		states[input] = fileState{mod: info.ModTime(), size: info.Size()}
+	}
+
+	fmt.Printf("watching %s for changes (Ctrl+C to stop)...\n", strings.Join(inputs, ", "))
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed := false
+			for _, input := range inputs {
+				info, err := os.Stat(input)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "watch: stat failed: %v\n", err)
+					continue
+				}
+				state := states[input]
+				if info.ModTime() == state.mod && info.Size() == state.size {
+					continue
+				}
+				states[input] = fileState{mod: info.ModTime(), size: info.Size()}
+				changed = true
+			}
+			if !changed {
+				continue
+			}
+
+			fmt.Printf("[%s] change detected, regenerating...\n", time.Now().Format("15:04:05"))
+			if err := regenerate(); err != nil {
+				fmt.Fprintf(os.Stderr, "regenerate failed: %v\n", err)
+				continue
+			}
+			if lr != nil {
+				lr.Reload()
+			}
+		case <-sigCh:
+			fmt.Println("stop watching")
+			return nil
+		}
+	}
+}
+
+type liveReloader struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+func newLiveReloader() *liveReloader {
+	return &liveReloader{clients: make(map[chan struct{}]struct{})}
+}
+
+// startLiveReload serves only the livereload endpoint on its own port.
+func startLiveReload(port int) (*liveReloader, string, error) {
+	lr := newLiveReloader()
+
+	mux := http.NewServeMux()
+	mux.Handle("/livereload", lr)
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "livereload server error: %v\n", err)
+		}
+	}()
+
+	url := fmt.Sprintf("http://%s/livereload", addr)
+	fmt.Printf("livereload server listening on %s\n", url)
+	return lr, url, nil
+}
+
+func (lr *liveReloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	client := make(chan struct{}, 1)
+	lr.mu.Lock()
+	lr.clients[client] = struct{}{}
+	lr.mu.Unlock()
+
+	// Send initial ping to establish connection
+	fmt.Fprintf(w, "data: ping\n\n")
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			lr.mu.Lock()
+			delete(lr.clients, client)
+			lr.mu.Unlock()
+			return
+		case <-client:
+			fmt.Fprintf(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (lr *liveReloader) Reload() {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	for ch := range lr.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
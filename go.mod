@@ -3,6 +3,10 @@ module ganttgen
 go 1.24.0
 
 require (
+	github.com/hajimehoshi/bitmapfont/v3 v3.2.0
+	golang.org/x/image v0.34.0
 	golang.org/x/text v0.32.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+require github.com/pierrec/lz4/v4 v4.1.21 // indirect
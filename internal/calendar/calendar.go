@@ -1,6 +1,8 @@
 package calendar
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -8,10 +10,36 @@ import (
 var (
 	holidaysMu sync.RWMutex
 	holidays   = map[time.Time]struct{}{}
+	// extraWorkdays are dates worked even though they fall on a weekend or holiday.
+	extraWorkdays = map[time.Time]struct{}{}
+	weekends      = defaultWeekends()
 	// allWorkdays treats weekends and holidays as workdays when true.
 	allWorkdays bool
 )
 
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "日": time.Sunday, "日曜": time.Sunday, "日曜日": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "月": time.Monday, "月曜": time.Monday, "月曜日": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "火": time.Tuesday, "火曜": time.Tuesday, "火曜日": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "水": time.Wednesday, "水曜": time.Wednesday, "水曜日": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "木": time.Thursday, "木曜": time.Thursday, "木曜日": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "金": time.Friday, "金曜": time.Friday, "金曜日": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "土": time.Saturday, "土曜": time.Saturday, "土曜日": time.Saturday,
+}
+
+func defaultWeekends() map[time.Weekday]bool {
+	return map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}
+}
+
+// ParseWeekday accepts English (sun, Sunday) and Japanese (日, 日曜日) weekday names.
+func ParseWeekday(name string) (time.Weekday, error) {
+	day, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown weekday %q", name)
+	}
+	return day, nil
+}
+
 // SetHolidays registers dates that should be treated as non-workdays.
 // Passing nil clears any previously configured holidays.
 func SetHolidays(dates []time.Time) {
@@ -24,6 +52,33 @@ func SetHolidays(dates []time.Time) {
 	}
 }
 
+// SetExtraWorkdays registers dates that are workdays even on a weekend or holiday.
+// Passing nil clears any previously configured dates.
+func SetExtraWorkdays(dates []time.Time) {
+	holidaysMu.Lock()
+	defer holidaysMu.Unlock()
+
+	extraWorkdays = make(map[time.Time]struct{}, len(dates))
+	for _, d := range dates {
+		extraWorkdays[DateOnly(d)] = struct{}{}
+	}
+}
+
+// SetWeekends sets the weekly non-working days. Passing nil restores Saturday and Sunday.
+func SetWeekends(days []time.Weekday) {
+	holidaysMu.Lock()
+	defer holidaysMu.Unlock()
+
+	if days == nil {
+		weekends = defaultWeekends()
+		return
+	}
+	weekends = make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		weekends[d] = true
+	}
+}
+
 // SetAllWorkdays controls whether weekends and holidays are treated as workdays.
 func SetAllWorkdays(enabled bool) {
 	holidaysMu.Lock()
@@ -41,21 +96,58 @@ func isHoliday(t time.Time) bool {
 	return ok
 }
 
-// IsWorkday reports whether the given date falls on a weekday (Mon-Fri).
+// IsWorkday reports whether the given date falls on a working weekday (Mon-Fri by default).
 func IsWorkday(t time.Time) bool {
 	day := DateOnly(t)
 	if allWorkdays {
 		return true
 	}
+	holidaysMu.RLock()
+	_, extra := extraWorkdays[day]
+	weekend := weekends[day.Weekday()]
+	holidaysMu.RUnlock()
+	if extra {
+		return true
+	}
 	if isHoliday(day) {
 		return false
 	}
-	switch day.Weekday() {
-	case time.Saturday, time.Sunday:
-		return false
-	default:
-		return true
+	return !weekend
+}
+
+// Weekends returns the weekly non-working days from Sunday to Saturday, or nil when
+// every day is a workday.
+func Weekends() []time.Weekday {
+	holidaysMu.RLock()
+	defer holidaysMu.RUnlock()
+	if allWorkdays {
+		return nil
+	}
+	var days []time.Weekday
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if weekends[d] {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// Exceptions lists the days from start to end that differ from the weekly pattern:
+// closed days are holidays on regular workdays, open days are workdays on weekends.
+func Exceptions(start, end time.Time) (closed, open []time.Time) {
+	weekly := make(map[time.Weekday]bool)
+	for _, d := range Weekends() {
+		weekly[d] = true
+	}
+	for day := DateOnly(start); !day.After(end); day = day.AddDate(0, 0, 1) {
+		switch weekend, work := weekly[day.Weekday()], IsWorkday(day); {
+		case weekend && work:
+			open = append(open, day)
+		case !weekend && !work:
+			closed = append(closed, day)
+		}
 	}
+	return closed, open
 }
 
 // DateOnly drops the time component for consistent date math.
@@ -77,6 +169,36 @@ func NextWorkdayAfter(t time.Time) time.Time {
 	return NextWorkday(DateOnly(t).AddDate(0, 0, 1))
 }
 
+// CountWorkdays returns the number of workdays from start to end, both inclusive.
+func CountWorkdays(start, end time.Time) int {
+	count := 0
+	for day := DateOnly(start); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if IsWorkday(day) {
+			count++
+		}
+	}
+	return count
+}
+
+// WorkdaysBetween returns the number of workdays after from up to and including to,
+// negated when to is before from.
+func WorkdaysBetween(from, to time.Time) int {
+	from, to = DateOnly(from), DateOnly(to)
+	if to.Before(from) {
+		return -WorkdaysBetween(to, from)
+	}
+	return CountWorkdays(from.AddDate(0, 0, 1), to)
+}
+
+// PreviousWorkdayBefore returns the last workday strictly before the provided date.
+func PreviousWorkdayBefore(t time.Time) time.Time {
+	day := DateOnly(t).AddDate(0, 0, -1)
+	for !IsWorkday(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
 // AddWorkdays moves forward by the given number of workdays (0 keeps the same day).
 func AddWorkdays(start time.Time, days int) time.Time {
 	current := NextWorkday(start)
@@ -74,6 +74,16 @@ func TestAddWorkdays(t *testing.T) {
 	}
 }
 
+func TestCountWorkdays(t *testing.T) {
+	t.Cleanup(func() { SetHolidays(nil) })
+	SetHolidays([]time.Time{mustDate(t, 2024, time.June, 5)})
+
+	got := CountWorkdays(mustDate(t, 2024, time.May, 31), mustDate(t, 2024, time.June, 7))
+	if got != 5 { // Fri, Mon, Tue, Thu, Fri
+		t.Fatalf("want 5 workdays, got %d", got)
+	}
+}
+
 func TestLoadHolidaysYAML(t *testing.T) {
 	t.Cleanup(func() { SetHolidays(nil) })
 
@@ -99,3 +109,68 @@ holidays:
 		t.Fatalf("expected adjacent weekday to be workday")
 	}
 }
+
+func TestSetWeekendsAndExtraWorkdays(t *testing.T) {
+	SetWeekends([]time.Weekday{time.Friday})
+	SetHolidays([]time.Time{mustDate(t, 2024, time.June, 4)})
+	SetExtraWorkdays([]time.Time{mustDate(t, 2024, time.June, 4), mustDate(t, 2024, time.June, 14)})
+	t.Cleanup(func() { SetWeekends(nil) })
+	t.Cleanup(func() { SetHolidays(nil) })
+	t.Cleanup(func() { SetExtraWorkdays(nil) })
+
+	if !IsWorkday(mustDate(t, 2024, time.June, 2)) { // Sunday
+		t.Fatalf("expected Sunday to be workday when only Friday is a weekend")
+	}
+	if IsWorkday(mustDate(t, 2024, time.June, 7)) { // Friday
+		t.Fatalf("expected Friday to be non-workday")
+	}
+	if !IsWorkday(mustDate(t, 2024, time.June, 4)) || !IsWorkday(mustDate(t, 2024, time.June, 14)) {
+		t.Fatalf("expected extra workdays to override holidays and weekends")
+	}
+}
+
+func TestWeekendsAndExceptions(t *testing.T) {
+	SetHolidays([]time.Time{mustDate(t, 2024, time.June, 4), mustDate(t, 2024, time.June, 8)})
+	SetExtraWorkdays([]time.Time{mustDate(t, 2024, time.June, 9)})
+	t.Cleanup(func() { SetHolidays(nil) })
+	t.Cleanup(func() { SetExtraWorkdays(nil) })
+
+	if got := Weekends(); len(got) != 2 || got[0] != time.Sunday || got[1] != time.Saturday {
+		t.Fatalf("unexpected weekends: %v", got)
+	}
+	closed, open := Exceptions(mustDate(t, 2024, time.June, 1), mustDate(t, 2024, time.June, 30))
+	if len(closed) != 1 || !closed[0].Equal(mustDate(t, 2024, time.June, 4)) {
+		t.Fatalf("expected the Tuesday holiday only (not the Saturday one), got %v", closed)
+	}
+	if len(open) != 1 || !open[0].Equal(mustDate(t, 2024, time.June, 9)) {
+		t.Fatalf("expected the Sunday workday, got %v", open)
+	}
+}
+
+func TestWorkdaysBetweenAndPreviousWorkday(t *testing.T) {
+	friday, monday := mustDate(t, 2024, time.June, 7), mustDate(t, 2024, time.June, 10)
+	if got := WorkdaysBetween(friday, monday); got != 1 {
+		t.Fatalf("expected 1 workday from Friday to Monday, got %d", got)
+	}
+	if got := WorkdaysBetween(monday, friday); got != -1 {
+		t.Fatalf("expected -1 workday from Monday back to Friday, got %d", got)
+	}
+	if got := WorkdaysBetween(friday, friday); got != 0 {
+		t.Fatalf("expected 0 for the same day, got %d", got)
+	}
+	if got := PreviousWorkdayBefore(monday); !got.Equal(friday) {
+		t.Fatalf("expected Friday before Monday, got %v", got)
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	for name, want := range map[string]time.Weekday{"sat": time.Saturday, "Sunday": time.Sunday, "水曜日": time.Wednesday, "金": time.Friday} {
+		got, err := ParseWeekday(name)
+		if err != nil || got != want {
+			t.Fatalf("%q: expected %v, got %v (%v)", name, want, got, err)
+		}
+	}
+	if _, err := ParseWeekday("someday"); err == nil {
+		t.Fatalf("expected error for unknown weekday")
+	}
+}
@@ -15,12 +15,7 @@ const dateLayout = "2006-01-02"
 //   - A top-level list of YYYY-MM-DD strings
 //   - A map with key "holidays" pointing to a list of YYYY-MM-DD strings
 func LoadHolidaysYAML(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("read holidays yaml: %w", err)
-	}
-
-	dates, err := parseHolidayDates(data)
+	dates, err := ReadHolidaysYAML(path)
 	if err != nil {
 		return err
 	}
@@ -28,22 +23,32 @@ func LoadHolidaysYAML(path string) error {
 	return nil
 }
 
+// ReadHolidaysYAML reads holiday dates from a YAML file without registering them.
+func ReadHolidaysYAML(path string) ([]time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read holidays yaml: %w", err)
+	}
+	return parseHolidayDates(data)
+}
+
 func parseHolidayDates(data []byte) ([]time.Time, error) {
 	var withKey struct {
 		Holidays []string `yaml:"holidays"`
 	}
 	if err := yaml.Unmarshal(data, &withKey); err == nil && len(withKey.Holidays) > 0 {
-		return parseDateStrings(withKey.Holidays)
+		return ParseDates(withKey.Holidays)
 	}
 
 	var list []string
 	if err := yaml.Unmarshal(data, &list); err != nil {
 		return nil, fmt.Errorf("decode holidays yaml: %w", err)
 	}
-	return parseDateStrings(list)
+	return ParseDates(list)
 }
 
-func parseDateStrings(values []string) ([]time.Time, error) {
+// ParseDates parses YYYY-MM-DD strings, skipping empty values.
+func ParseDates(values []string) ([]time.Time, error) {
 	dates := make([]time.Time, 0, len(values))
 	for _, v := range values {
 		if v == "" {
@@ -51,7 +56,7 @@ func parseDateStrings(values []string) ([]time.Time, error) {
 		}
 		parsed, err := time.ParseInLocation(dateLayout, v, time.Local)
 		if err != nil {
-			return nil, fmt.Errorf("parse holiday %q: %w", v, err)
+			return nil, fmt.Errorf("parse date %q: %w", v, err)
 		}
 		dates = append(dates, parsed)
 	}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/csvinput"
+	"ganttgen/internal/model"
+	"ganttgen/internal/renderer"
+)
+
+// FileNames are the config file names discovered next to the input CSV, in priority order.
+var FileNames = []string{"ganttgen.yaml", "ganttgen.yml"}
+
+// DefaultLiveReloadPort is the livereload port used when none is configured.
+const DefaultLiveReloadPort = 35729
+
+// Config is the project configuration loaded from ganttgen.yaml:
+//
+//	format: html
+//	output: docs/plan.html
+//	holidays: [../japanese_holidays.yaml]
+//	calendar:
+//	  weekends: [sat, sun]
+//	  holidays: [2026-12-29]
+//	  workdays: [2026-01-10]
+//	input:
+//	  encoding: shift_jis
+//	  anchors: {project: 2026-04-01}
+//	columns:
+//	  name: [Task Name]
+//	statuses:
+//	  - {name: 進行中, color: "#2563eb", semantic: in-progress}
+//	timesheet: timesheet.csv
+//	hours_per_day: 7.5
+//	theme:
+//	  accent: "#0f766e"
+//	render:
+//	  title: Release plan
+//	  from: 2026-04-01
+//	  side_columns: [name, status]
+//	  scale: 2
+//	  page_size: a3
+//	livereload:
+//	  port: 35730
+//
+// Relative paths are resolved against the directory of the config file.
+type Config struct {
+	Format       string                         `yaml:"format,omitempty"`
+	Output       string                         `yaml:"output,omitempty"`
+	Holidays     csvinput.StringList            `yaml:"holidays,omitempty"`
+	Calendar     Calendar                       `yaml:"calendar,omitempty"`
+	Input        Input                          `yaml:"input,omitempty"`
+	ColumnsFile  string                         `yaml:"columns_file,omitempty"`
+	Columns      map[string]csvinput.StringList `yaml:"columns,omitempty"`
+	Status       csvinput.StatusValues          `yaml:"status,omitempty"`
+	StatusesFile string                         `yaml:"statuses_file,omitempty"`
+	Statuses     []model.StatusDef              `yaml:"statuses,omitempty"`
+	Timesheet    string                         `yaml:"timesheet,omitempty"`
+	HoursPerDay  float64                        `yaml:"hours_per_day,omitempty"`
+	Theme        renderer.Theme                 `yaml:"theme,omitempty"`
+	Render       Render                         `yaml:"render,omitempty"`
+	Watch        bool                           `yaml:"watch,omitempty"`
+	LiveReload   LiveReload                     `yaml:"livereload"`
+
+	// Path is the file the configuration was loaded from, empty for defaults.
+	Path string `yaml:"-"`
+}
+
+// Calendar configures working days on top of the holiday files.
+type Calendar struct {
+	AllWorkdays bool     `yaml:"all_workdays,omitempty"`
+	Weekends    []string `yaml:"weekends,omitempty"`
+	Holidays    []string `yaml:"holidays,omitempty"`
+	Workdays    []string `yaml:"workdays,omitempty"`
+}
+
+// Input configures how the CSV files are read.
+type Input struct {
+	Encoding  string            `yaml:"encoding,omitempty"`
+	Delimiter string            `yaml:"delimiter,omitempty"`
+	Anchors   map[string]string `yaml:"anchors,omitempty"`
+}
+
+// Render configures the generated chart.
+type Render struct {
+	Title     string `yaml:"title,omitempty"`
+	CellWidth int    `yaml:"cell_width,omitempty"`
+	HideNotes bool   `yaml:"hide_notes,omitempty"`
+	// From and To (YYYY-MM-DD) limit the dates shown; empty fits the chart to the tasks.
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+	// SideColumns picks the columns drawn left of the timeline in image formats.
+	SideColumns []string `yaml:"side_columns,omitempty"`
+	// Scale is the pixel ratio of PNG output.
+	Scale float64 `yaml:"scale,omitempty"`
+	// PageSize is the paper size of PDF output (a3 or a4, landscape).
+	PageSize string `yaml:"page_size,omitempty"`
+	// ICSEvents selects the events of calendar output (all or milestones).
+	ICSEvents string `yaml:"ics_events,omitempty"`
+	// TextWidth is the width of text output in columns; zero uses the terminal width.
+	TextWidth int `yaml:"text_width,omitempty"`
+	// Color selects ANSI colors in text output: auto, always or never.
+	Color string `yaml:"color,omitempty"`
+}
+
+// DateRange parses From and To; unset bounds are zero.
+func (r Render) DateRange() (from, to time.Time, err error) {
+	dates, err := calendar.ParseDates([]string{r.From, r.To})
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("render date range: %w", err)
+	}
+	if r.From != "" {
+		from, dates = dates[0], dates[1:]
+	}
+	if r.To != "" {
+		to = dates[0]
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("render date range: to %s is before from %s", r.To, r.From)
+	}
+	return from, to, nil
+}
+
+// LiveReload configures the livereload server.
+type LiveReload struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	Port    int  `yaml:"port"`
+}
+
+// Default returns the configuration used when no file is found.
+func Default() Config {
+	return Config{
+		Format:     "html",
+		Input:      Input{Encoding: "auto", Delimiter: "auto"},
+		LiveReload: LiveReload{Port: DefaultLiveReloadPort},
+	}
+}
+
+// Discover returns the first config file found in dir, or "" when there is none.
+func Discover(dir string) string {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// Load reads a config file on top of the defaults and resolves its relative paths.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("decode config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("config %s: %w", path, err)
+	}
+	cfg.Path = path
+	cfg.resolvePaths(filepath.Dir(path))
+	return cfg, nil
+}
+
+// LoadOrDiscover loads path when given, otherwise the config discovered in dir,
+// otherwise the defaults.
+func LoadOrDiscover(path, dir string) (Config, error) {
+	if path == "" {
+		path = Discover(dir)
+	}
+	if path == "" {
+		return Default(), nil
+	}
+	return Load(path)
+}
+
+func (c *Config) validate() error {
+	if c.LiveReload.Port < 0 || c.LiveReload.Port > 65535 {
+		return fmt.Errorf("livereload port %d out of range", c.LiveReload.Port)
+	}
+	if c.HoursPerDay < 0 {
+		return errors.New("hours_per_day must be positive")
+	}
+	if c.Render.CellWidth < 0 {
+		return errors.New("render.cell_width must be positive")
+	}
+	if c.Render.Scale < 0 {
+		return errors.New("render.scale must be positive")
+	}
+	if _, _, err := c.Render.DateRange(); err != nil {
+		return err
+	}
+	if err := model.ValidateStatuses(c.Statuses); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Config) resolvePaths(dir string) {
+	resolve := func(p string) string {
+		if p == "" || p == "-" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	c.Output = resolve(c.Output)
+	c.ColumnsFile = resolve(c.ColumnsFile)
+	c.StatusesFile = resolve(c.StatusesFile)
+	c.Timesheet = resolve(c.Timesheet)
+	for i, h := range c.Holidays {
+		c.Holidays[i] = resolve(h)
+	}
+}
+
+// YAML renders the configuration in the config file format.
+func (c Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
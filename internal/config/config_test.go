@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestLoadResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ganttgen.yaml")
+	writeFile(t, path, `output: docs/plan.html
+holidays: [../holidays.yaml, /etc/holidays.yaml]
+statuses_file: statuses.yaml
+timesheet: hours/timesheet.csv
+render:
+  title: Release plan
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Output != filepath.Join(dir, "docs", "plan.html") {
+		t.Fatalf("output not resolved: %q", cfg.Output)
+	}
+	if cfg.Holidays[0] != filepath.Join(filepath.Dir(dir), "holidays.yaml") || cfg.Holidays[1] != "/etc/holidays.yaml" {
+		t.Fatalf("holidays not resolved: %v", cfg.Holidays)
+	}
+	if cfg.StatusesFile != filepath.Join(dir, "statuses.yaml") {
+		t.Fatalf("statuses file not resolved: %q", cfg.StatusesFile)
+	}
+	if cfg.Timesheet != filepath.Join(dir, "hours", "timesheet.csv") {
+		t.Fatalf("timesheet not resolved: %q", cfg.Timesheet)
+	}
+	if cfg.Render.Title != "Release plan" || cfg.LiveReload.Port != DefaultLiveReloadPort || cfg.Input.Encoding != "auto" {
+		t.Fatalf("expected file values on top of defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadOrDiscover(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadOrDiscover("", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Path != "" || cfg.LiveReload.Port != DefaultLiveReloadPort {
+		t.Fatalf("expected defaults without a config file, got %+v", cfg)
+	}
+
+	writeFile(t, filepath.Join(dir, "ganttgen.yml"), "livereload:\n  port: 35730\n")
+	cfg, err = LoadOrDiscover("", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LiveReload.Port != 35730 || filepath.Base(cfg.Path) != "ganttgen.yml" {
+		t.Fatalf("expected discovered config, got %+v", cfg)
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ganttgen.yaml")
+	writeFile(t, path, "statuses:\n  - name: 保留\n    semantic: paused\n")
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unknown semantic") {
+		t.Fatalf("expected semantic error, got %v", err)
+	}
+
+	writeFile(t, path, "render:\n  from: 2026-05-01\n  to: 2026-04-01\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "before from") {
+		t.Fatalf("expected date range error, got %v", err)
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	cfg := Default()
+	cfg.Output = "plan.html"
+	cfg.Calendar.Weekends = []string{"sun"}
+	data, err := cfg.YAML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), "output: plan.html") || !strings.Contains(string(data), "  weekends:\n    - sun") {
+		t.Fatalf("unexpected yaml:\n%s", data)
+	}
+}
@@ -0,0 +1,216 @@
+package csvinput
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// projectAnchor is the anchor that bare offsets such as "+2w" are relative to.
+const projectAnchor = "project"
+
+var (
+	offsetExpr   = regexp.MustCompile(`^(.*?)((?:\s*[+-]\s*\d+\s*[dwmDWM])+)$`)
+	offsetTerm   = regexp.MustCompile(`([+-])\s*(\d+)\s*([dwmDWM])`)
+	anchorName   = regexp.MustCompile(`^@([\p{L}\p{N}_\-]+)$`)
+	eraDateExpr  = regexp.MustCompile(`^(令和|平成|昭和|[RHSrhs])\s*(\d+|元)\s*[./\-年]\s*(\d+)\s*[./\-月]\s*(\d+)\s*日?$`)
+	kanjiDate    = regexp.MustCompile(`^(\d{4})\s*年\s*(\d+)\s*月\s*(\d+)\s*日?$`)
+	monthDayExpr = regexp.MustCompile(`^(\d{1,2})\s*(?:/|月)\s*(\d{1,2})\s*日?$`)
+)
+
+type era struct {
+	names []string
+	start int
+}
+
+var eras = []era{
+	{names: []string{"令和", "r"}, start: 2019},
+	{names: []string{"平成", "h"}, start: 1989},
+	{names: []string{"昭和", "s"}, start: 1926},
+}
+
+type anchorDef struct {
+	expr   string
+	source string
+}
+
+// dateContext resolves date expressions in plan and actual columns.
+// Supported forms:
+//   - absolute dates (YYYY-MM-DD, YYYY/MM/DD, YYYY年M月D日)
+//   - Japanese era dates (R8.1.5, 令和8年1月5日)
+//   - month/day shorthand (1/5, 1月5日) resolved against the project year
+//   - "today" and anchors ("@kickoff") optionally followed by offsets ("+5d", "-1w", "+1m")
+//   - bare offsets ("+2w") relative to the project anchor, or today when none is set
+type dateContext struct {
+	today    time.Time
+	anchors  map[string]anchorDef
+	resolved map[string]time.Time
+	active   map[string]bool
+}
+
+func newDateContext(opts Options) *dateContext {
+	today := opts.Today
+	if today.IsZero() {
+		today = time.Now()
+	}
+	ctx := &dateContext{
+		today:    time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local),
+		anchors:  make(map[string]anchorDef, len(opts.Anchors)),
+		resolved: make(map[string]time.Time),
+		active:   make(map[string]bool),
+	}
+	for name, expr := range opts.Anchors {
+		ctx.anchors[normalizeAnchor(name)] = anchorDef{expr: expr, source: "option"}
+	}
+	return ctx
+}
+
+// define registers an anchor unless it is already set (options take precedence over CSV rows).
+func (c *dateContext) define(name, expr, source string) {
+	key := normalizeAnchor(name)
+	if _, exists := c.anchors[key]; exists {
+		return
+	}
+	c.anchors[key] = anchorDef{expr: expr, source: source}
+}
+
+func (c *dateContext) anchor(name string) (time.Time, error) {
+	key := normalizeAnchor(name)
+	if t, ok := c.resolved[key]; ok {
+		return t, nil
+	}
+	def, ok := c.anchors[key]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown anchor @%s", key)
+	}
+	if c.active[key] {
+		return time.Time{}, fmt.Errorf("anchor @%s refers to itself", key)
+	}
+	c.active[key] = true
+	defer delete(c.active, key)
+
+	t, err := c.parse(def.expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("anchor @%s (%s): %w", key, def.source, err)
+	}
+	c.resolved[key] = t
+	return t, nil
+}
+
+// baseYear returns the year used for month/day shorthand.
+func (c *dateContext) baseYear() int {
+	if _, ok := c.anchors[projectAnchor]; ok {
+		if t, err := c.anchor(projectAnchor); err == nil {
+			return t.Year()
+		}
+	}
+	return c.today.Year()
+}
+
+func (c *dateContext) parse(raw string) (time.Time, error) {
+	expr := strings.TrimSpace(raw)
+	if expr == "" {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+	if m := offsetExpr.FindStringSubmatch(expr); m != nil {
+		base := strings.TrimSpace(m[1])
+		var (
+			t   time.Time
+			err error
+		)
+		if base == "" {
+			t, err = c.projectBase()
+		} else {
+			t, err = c.parseBase(base)
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		return applyOffsets(t, m[2]), nil
+	}
+	return c.parseBase(expr)
+}
+
+func (c *dateContext) projectBase() (time.Time, error) {
+	if _, ok := c.anchors[projectAnchor]; ok {
+		return c.anchor(projectAnchor)
+	}
+	return c.today, nil
+}
+
+func (c *dateContext) parseBase(expr string) (time.Time, error) {
+	if t, err := parseDate(expr); err == nil {
+		return t, nil
+	}
+	lower := strings.ToLower(expr)
+	if lower == "today" || expr == "今日" {
+		return c.today, nil
+	}
+	if m := anchorName.FindStringSubmatch(expr); m != nil {
+		return c.anchor(m[1])
+	}
+	if m := eraDateExpr.FindStringSubmatch(expr); m != nil {
+		return parseEraDate(m[1], m[2], m[3], m[4])
+	}
+	if m := kanjiDate.FindStringSubmatch(expr); m != nil {
+		return buildDate(atoi(m[1]), atoi(m[2]), atoi(m[3]))
+	}
+	if m := monthDayExpr.FindStringSubmatch(expr); m != nil {
+		return buildDate(c.baseYear(), atoi(m[1]), atoi(m[2]))
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD, YYYY/MM/DD, M/D, an era date, today, @anchor or an offset like +2w)", expr)
+}
+
+func applyOffsets(t time.Time, offsets string) time.Time {
+	for _, m := range offsetTerm.FindAllStringSubmatch(offsets, -1) {
+		n := atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		switch strings.ToLower(m[3]) {
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "m":
+			t = t.AddDate(0, n, 0)
+		}
+	}
+	return t
+}
+
+func parseEraDate(name, year, month, day string) (time.Time, error) {
+	lower := strings.ToLower(name)
+	for _, e := range eras {
+		for _, n := range e.names {
+			if n != lower {
+				continue
+			}
+			y := 1
+			if year != "元" {
+				y = atoi(year)
+			}
+			return buildDate(e.start+y-1, atoi(month), atoi(day))
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown era %q", name)
+}
+
+func buildDate(year, month, day int) (time.Time, error) {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %04d-%02d-%02d", year, month, day)
+	}
+	return t, nil
+}
+
+func normalizeAnchor(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
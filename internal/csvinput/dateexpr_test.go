@@ -0,0 +1,62 @@
+package csvinput
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateContextParse(t *testing.T) {
+	ctx := newDateContext(Options{
+		Today:   time.Date(2026, time.January, 14, 9, 30, 0, 0, time.Local),
+		Anchors: map[string]string{"project": "2026-04-01", "kickoff": "@project+1w"},
+	})
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{expr: "2026-02-03", want: "2026-02-03"},
+		{expr: "today", want: "2026-01-14"},
+		{expr: "today+3d", want: "2026-01-17"},
+		{expr: "+2w", want: "2026-04-15"},
+		{expr: "-1d", want: "2026-03-31"},
+		{expr: "@kickoff", want: "2026-04-08"},
+		{expr: "@kickoff+5d", want: "2026-04-13"},
+		{expr: "@project+1m", want: "2026-05-01"},
+		{expr: "5/7", want: "2026-05-07"},
+		{expr: "5月7日", want: "2026-05-07"},
+		{expr: "R8.1.5", want: "2026-01-05"},
+		{expr: "令和8年1月5日", want: "2026-01-05"},
+		{expr: "平成31年4月30日", want: "2019-04-30"},
+		{expr: "令和元年5月1日", want: "2019-05-01"},
+		{expr: "2026年3月2日", want: "2026-03-02"},
+	}
+	for _, tc := range cases {
+		got, err := ctx.parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.expr, err)
+		}
+		if got.Format("2006-01-02") != tc.want {
+			t.Fatalf("%q: want %s, got %s", tc.expr, tc.want, got.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestDateContextParseErrors(t *testing.T) {
+	ctx := newDateContext(Options{Anchors: map[string]string{"loop": "@loop+1d"}})
+	for _, expr := range []string{"@missing", "@loop", "2/30", "someday"} {
+		if _, err := ctx.parse(expr); err == nil {
+			t.Fatalf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestDateContextMonthDayWithoutProjectUsesTodayYear(t *testing.T) {
+	ctx := newDateContext(Options{Today: time.Date(2025, time.December, 1, 0, 0, 0, 0, time.Local)})
+	got, err := ctx.parse("1/5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Format("2006-01-02") != "2025-01-05" {
+		t.Fatalf("unexpected date: %s", got.Format("2006-01-02"))
+	}
+}
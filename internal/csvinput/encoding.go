@@ -0,0 +1,241 @@
+package csvinput
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+type encodingKind int
+
+const (
+	encUTF8 encodingKind = iota
+	encShiftJIS
+	encEUCJP
+	encUTF16LE
+	encUTF16BE
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+func (k encodingKind) String() string {
+	switch k {
+	case encUTF8:
+		return "UTF-8"
+	case encShiftJIS:
+		return "Shift_JIS"
+	case encEUCJP:
+		return "EUC-JP"
+	case encUTF16LE:
+		return "UTF-16LE"
+	case encUTF16BE:
+		return "UTF-16BE"
+	default:
+		return "unknown"
+	}
+}
+
+func (k encodingKind) decoder() encoding.Encoding {
+	switch k {
+	case encShiftJIS:
+		return japanese.ShiftJIS
+	case encEUCJP:
+		return japanese.EUCJP
+	case encUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case encUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	default:
+		return nil
+	}
+}
+
+// parseEncodingName maps a user supplied encoding name to an encodingKind.
+// ok is false for "auto" (or empty), meaning the encoding should be detected.
+func parseEncodingName(name string) (kind encodingKind, ok bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return encUTF8, false, nil
+	case "utf-8", "utf8", "utf-8-sig":
+		return encUTF8, true, nil
+	case "shift_jis", "shift-jis", "sjis", "cp932", "windows-31j", "ms932":
+		return encShiftJIS, true, nil
+	case "euc-jp", "eucjp", "euc_jp":
+		return encEUCJP, true, nil
+	case "utf-16", "utf16":
+		return encUTF16LE, true, nil // BOM, when present, still decides the byte order
+	case "utf-16le", "utf16le":
+		return encUTF16LE, true, nil
+	case "utf-16be", "utf16be":
+		return encUTF16BE, true, nil
+	default:
+		return encUTF8, false, fmt.Errorf("unsupported encoding %q (use auto, utf-8, shift_jis, euc-jp, utf-16, utf-16le or utf-16be)", name)
+	}
+}
+
+// decodeCSVBytes detects (or applies the forced) encoding and returns UTF-8 bytes without a BOM.
+func decodeCSVBytes(data []byte, forced string) ([]byte, error) {
+	kind, err := inputEncoding(data, forced)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAs(data, kind)
+}
+
+// inputEncoding returns the forced encoding, or the detected one when forced is "auto".
+func inputEncoding(data []byte, forced string) (encodingKind, error) {
+	kind, ok, err := parseEncodingName(forced)
+	if err != nil {
+		return encUTF8, err
+	}
+	if !ok {
+		kind = detectEncoding(data)
+	} else if bomKind, found := detectBOM(data); found && (kind == encUTF16LE || kind == encUTF16BE) && bomKind != encUTF8 {
+		kind = bomKind
+	}
+	return kind, nil
+}
+
+// encodeAs converts UTF-8 data back to kind, the reverse of decodeAs. bom is written in
+// front unchanged.
+func encodeAs(data []byte, kind encodingKind, bom []byte) ([]byte, error) {
+	if kind == encUTF8 {
+		return append(append([]byte(nil), bom...), data...), nil
+	}
+	enc := kind.decoder()
+	if enc == nil {
+		return nil, fmt.Errorf("unknown encoding")
+	}
+	encoded, err := enc.NewEncoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", kind, err)
+	}
+	return append(append([]byte(nil), bom...), encoded...), nil
+}
+
+func decodeAs(data []byte, kind encodingKind) ([]byte, error) {
+	switch kind {
+	case encUTF8:
+		data = bytes.TrimPrefix(data, bomUTF8)
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("csv is not valid utf-8")
+		}
+		return data, nil
+	case encUTF16LE:
+		data = bytes.TrimPrefix(data, bomUTF16LE)
+	case encUTF16BE:
+		data = bytes.TrimPrefix(data, bomUTF16BE)
+	}
+	dec := kind.decoder()
+	if dec == nil {
+		return nil, fmt.Errorf("unknown encoding")
+	}
+	reader := transform.NewReader(bytes.NewReader(data), dec.NewDecoder())
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", kind, err)
+	}
+	return bytes.TrimPrefix(decoded, bomUTF8), nil
+}
+
+// bomPrefix returns the byte order mark at the start of data, if any.
+func bomPrefix(data []byte) []byte {
+	for _, bom := range [][]byte{bomUTF8, bomUTF16LE, bomUTF16BE} {
+		if bytes.HasPrefix(data, bom) {
+			return bom
+		}
+	}
+	return nil
+}
+
+func detectBOM(data []byte) (encodingKind, bool) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return encUTF8, true
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return encUTF16LE, true
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return encUTF16BE, true
+	}
+	return encUTF8, false
+}
+
+// detectEncoding checks for a BOM first, then uses the first line (header) as a sample.
+// Headers that are not valid UTF-8 are classified as EUC-JP when their byte structure
+// allows it and as Shift_JIS otherwise.
+func detectEncoding(data []byte) encodingKind {
+	if kind, ok := detectBOM(data); ok {
+		return kind
+	}
+	if len(data) >= 2 {
+		// BOM-less UTF-16: an ASCII header character leaves a zero byte on one side.
+		switch {
+		case data[0] == 0 && data[1] != 0:
+			return encUTF16BE
+		case data[0] != 0 && data[1] == 0:
+			return encUTF16LE
+		}
+	}
+	sample := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		sample = data[:idx]
+	}
+	if utf8.Valid(sample) {
+		if utf8.Valid(data) {
+			return encUTF8
+		}
+		// ASCII-only header: judge the legacy encoding from the whole input instead.
+		sample = data
+	}
+	if looksLikeEUCJP(sample) {
+		return encEUCJP
+	}
+	return encShiftJIS
+}
+
+// looksLikeEUCJP reports whether every non-ASCII byte sequence is a well-formed EUC-JP character.
+// Shift_JIS kanji/kana lead bytes (0x81-0x9F) are rejected, which keeps typical SJIS text out.
+func looksLikeEUCJP(data []byte) bool {
+	multibyte := false
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			continue
+		case b == 0x8E: // half-width katakana
+			if i+1 >= len(data) || data[i+1] < 0xA1 || data[i+1] > 0xDF {
+				return false
+			}
+			i++
+		case b == 0x8F: // JIS X 0212
+			if i+2 >= len(data) || !isEUCByte(data[i+1]) || !isEUCByte(data[i+2]) {
+				return false
+			}
+			i += 2
+		case isEUCByte(b):
+			if i+1 >= len(data) || !isEUCByte(data[i+1]) {
+				return false
+			}
+			i++
+		default:
+			return false
+		}
+		multibyte = true
+	}
+	return multibyte
+}
+
+func isEUCByte(b byte) bool {
+	return b >= 0xA1 && b <= 0xFE
+}
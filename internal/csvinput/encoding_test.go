@@ -0,0 +1,90 @@
+package csvinput
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const encodingSample = "タスク名,開始,終了,期間,依存\n計画,2024-06-03,,2d,\n"
+
+func TestDecodeCSVBytesDetectsEncodings(t *testing.T) {
+	utf16le, _, err := transform.String(unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder(), encodingSample)
+	if err != nil {
+		t.Fatalf("encode utf-16le: %v", err)
+	}
+	utf16be, _, err := transform.String(unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder(), encodingSample)
+	if err != nil {
+		t.Fatalf("encode utf-16be: %v", err)
+	}
+	eucjp, _, err := transform.String(japanese.EUCJP.NewEncoder(), encodingSample)
+	if err != nil {
+		t.Fatalf("encode euc-jp: %v", err)
+	}
+	sjis, _, err := transform.String(japanese.ShiftJIS.NewEncoder(), encodingSample)
+	if err != nil {
+		t.Fatalf("encode shift_jis: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+		want encodingKind
+	}{
+		{name: "utf-8", data: []byte(encodingSample), want: encUTF8},
+		{name: "utf-8 bom", data: append([]byte{0xEF, 0xBB, 0xBF}, encodingSample...), want: encUTF8},
+		{name: "utf-16le bom", data: []byte(utf16le), want: encUTF16LE},
+		{name: "utf-16be bom", data: []byte(utf16be), want: encUTF16BE},
+		{name: "euc-jp", data: []byte(eucjp), want: encEUCJP},
+		{name: "shift_jis", data: []byte(sjis), want: encShiftJIS},
+	}
+	for _, tc := range cases {
+		if got := detectEncoding(tc.data); got != tc.want {
+			t.Fatalf("%s: detected %s, want %s", tc.name, got, tc.want)
+		}
+		decoded, err := decodeCSVBytes(tc.data, "")
+		if err != nil {
+			t.Fatalf("%s: decode: %v", tc.name, err)
+		}
+		if string(decoded) != encodingSample {
+			t.Fatalf("%s: decoded mismatch: %q", tc.name, decoded)
+		}
+	}
+}
+
+func TestDecodeCSVBytesForcedEncoding(t *testing.T) {
+	eucjp, _, err := transform.String(japanese.EUCJP.NewEncoder(), encodingSample)
+	if err != nil {
+		t.Fatalf("encode euc-jp: %v", err)
+	}
+	decoded, err := decodeCSVBytes([]byte(eucjp), "euc-jp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(decoded) != encodingSample {
+		t.Fatalf("decoded mismatch: %q", decoded)
+	}
+	if _, err := decodeCSVBytes([]byte(encodingSample), "latin-9"); err == nil {
+		t.Fatalf("expected unsupported encoding error")
+	}
+}
+
+func TestReadStripsUTF8BOMFromHeader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bom.csv")
+	if err := os.WriteFile(path, append([]byte{0xEF, 0xBB, 0xBF}, encodingSample...), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Name != "計画" {
+		t.Fatalf("unexpected tasks: %#v", tasks)
+	}
+}
@@ -0,0 +1,68 @@
+package csvinput
+
+import (
+	"fmt"
+	"strings"
+
+	"ganttgen/internal/model"
+)
+
+// intervalSeparators split the two dates of an interval. A plain '-' is tried last
+// because it also appears inside dates.
+var intervalSeparators = []string{"..", "〜", "～", "~"}
+
+// parseIntervals parses a list of date intervals such as "2026-02-02..2026-02-13" or
+// "2026/1/5-1/7; 1/12-1/14". A single date is a one-day interval.
+func parseIntervals(raw string, dates *dateContext, delimiter rune) ([]model.Interval, error) {
+	var intervals []model.Interval
+	for _, part := range splitList(raw, delimiter) {
+		iv, err := parseInterval(part, dates)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, iv)
+	}
+	return intervals, nil
+}
+
+func parseInterval(raw string, dates *dateContext) (model.Interval, error) {
+	for _, sep := range intervalSeparators {
+		if from, to, ok := strings.Cut(raw, sep); ok {
+			return newInterval(raw, strings.TrimSpace(from), strings.TrimSpace(to), dates)
+		}
+	}
+	if start, err := dates.parse(raw); err == nil {
+		return model.Interval{Start: start, End: start}, nil
+	}
+	// "2026/1/5-1/7" or "2026-01-05-2026-01-07": use the first '-' with a date on both sides.
+	for i := strings.Index(raw, "-"); i >= 0; {
+		from, to := strings.TrimSpace(raw[:i]), strings.TrimSpace(raw[i+1:])
+		if iv, err := newInterval(raw, from, to, dates); err == nil {
+			return iv, nil
+		}
+		next := strings.Index(raw[i+1:], "-")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return model.Interval{}, fmt.Errorf("invalid interval %q (expected START..END)", raw)
+}
+
+func newInterval(raw, from, to string, dates *dateContext) (model.Interval, error) {
+	if from == "" || to == "" {
+		return model.Interval{}, fmt.Errorf("invalid interval %q (expected START..END)", raw)
+	}
+	start, err := dates.parse(from)
+	if err != nil {
+		return model.Interval{}, err
+	}
+	end, err := dates.parse(to)
+	if err != nil {
+		return model.Interval{}, err
+	}
+	if end.Before(start) {
+		return model.Interval{}, fmt.Errorf("interval %q ends before it starts", raw)
+	}
+	return model.Interval{Start: start, End: end}, nil
+}
@@ -0,0 +1,47 @@
+package csvinput
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseIntervals(t *testing.T) {
+	dates := newDateContext(Options{Anchors: map[string]string{"project": "2026-01-05"}})
+	cases := []struct {
+		raw  string
+		want []string
+	}{
+		{raw: "2026-02-02..2026-02-13", want: []string{"2026-02-02", "2026-02-13"}},
+		{raw: "2026/1/5-1/7; 1/12-1/14", want: []string{"2026-01-05", "2026-01-07", "2026-01-12", "2026-01-14"}},
+		{raw: "2026-01-05-2026-01-07", want: []string{"2026-01-05", "2026-01-07"}},
+		{raw: "1/20〜1/23, 2026-02-11", want: []string{"2026-01-20", "2026-01-23", "2026-02-11", "2026-02-11"}},
+		{raw: "@project+1w..@project+2w", want: []string{"2026-01-12", "2026-01-19"}},
+	}
+	for _, tc := range cases {
+		got, err := parseIntervals(tc.raw, dates, ',')
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.raw, err)
+		}
+		var formatted []string
+		for _, iv := range got {
+			formatted = append(formatted, iv.Start.Format("2006-01-02"), iv.End.Format("2006-01-02"))
+		}
+		if len(formatted) != len(tc.want) {
+			t.Fatalf("%q: want %v, got %v", tc.raw, tc.want, formatted)
+		}
+		for i := range formatted {
+			if formatted[i] != tc.want[i] {
+				t.Fatalf("%q: want %v, got %v", tc.raw, tc.want, formatted)
+			}
+		}
+	}
+}
+
+func TestParseIntervalsRejectsReversedRange(t *testing.T) {
+	dates := newDateContext(Options{Today: time.Date(2026, time.January, 5, 0, 0, 0, 0, time.Local)})
+	for _, raw := range []string{"2026-02-13..2026-02-02", "2026-02-02..", "soon"} {
+		if _, err := parseIntervals(raw, dates, ','); err == nil {
+			t.Fatalf("%q: expected error", raw)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package csvinput
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ColumnMapping is the user-defined header mapping loaded from YAML:
+//
+//	columns:
+//	  name: [Task Name, 作業名]
+//	  start: Planned Start
+//	  assignee: 担当者        # non-canonical targets rename a custom column
+//	status:
+//	  cancelled: [取り下げ]
+//	  completed: [済, done]
+type ColumnMapping struct {
+	Columns map[string]StringList `yaml:"columns"`
+	Status  StatusValues          `yaml:"status"`
+}
+
+// StatusValues lists extra status values that count as cancelled or completed.
+type StatusValues struct {
+	Cancelled StringList `yaml:"cancelled"`
+	Completed StringList `yaml:"completed"`
+}
+
+// StringList accepts either a single YAML scalar or a sequence of scalars.
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*l = StringList{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var values []string
+		if err := node.Decode(&values); err != nil {
+			return err
+		}
+		*l = values
+		return nil
+	default:
+		return fmt.Errorf("line %d: expected a string or a list of strings", node.Line)
+	}
+}
+
+// LoadColumnMapping reads a column mapping YAML file.
+func LoadColumnMapping(path string) (ColumnMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ColumnMapping{}, fmt.Errorf("read column mapping: %w", err)
+	}
+	var mapping ColumnMapping
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return ColumnMapping{}, fmt.Errorf("decode column mapping: %w", err)
+	}
+	return mapping, nil
+}
+
+// headerAliases flattens the mapping into lower-cased header name -> target column.
+func headerAliases(columns map[string]StringList) map[string]string {
+	if len(columns) == 0 {
+		return nil
+	}
+	aliases := make(map[string]string)
+	for target, headers := range columns {
+		target = strings.TrimSpace(target)
+		if canonical, ok := columnAliases[strings.ToLower(target)]; ok {
+			target = canonical
+		} else if _, ok := knownColumns[strings.ToLower(target)]; ok {
+			target = strings.ToLower(target)
+		}
+		for _, h := range headers {
+			aliases[strings.ToLower(strings.TrimSpace(h))] = target
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,59 @@
+package csvinput
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ganttgen/internal/model"
+)
+
+func TestReadWithColumnMapping(t *testing.T) {
+	dir := t.TempDir()
+	mappingPath := filepath.Join(dir, "columns.yaml")
+	mapping := `
+columns:
+  name: [Task Name]
+  start: Planned Start
+  end: Planned End
+  duration: 工数
+  depends_on: [Predecessors]
+  status: State
+  assignee: 担当者
+status:
+  cancelled: [取り下げ]
+  completed: 済
+`
+	if err := os.WriteFile(mappingPath, []byte(mapping), 0o644); err != nil {
+		t.Fatalf("write mapping: %v", err)
+	}
+	content := `Task Name,Planned Start,Planned End,工数,Predecessors,State,担当者
+Design,2024-06-03,,2d,,済,Alice
+Build,,,3d,Design,取り下げ,Bob
+`
+	csvPath := filepath.Join(dir, "input.csv")
+	if err := os.WriteFile(csvPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	loaded, err := LoadColumnMapping(mappingPath)
+	if err != nil {
+		t.Fatalf("load mapping: %v", err)
+	}
+	model.SetStatusAliases(loaded.Status.Cancelled, loaded.Status.Completed)
+	t.Cleanup(func() { model.SetStatusAliases(nil, nil) })
+
+	tasks, customCols, _, err := ReadWithOptions(csvPath, Options{Columns: loaded.Columns})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].DependsOn[0] != "Design" || tasks[1].DurationDays != 3 {
+		t.Fatalf("unexpected tasks: %#v", tasks)
+	}
+	if len(customCols) != 1 || customCols[0] != "assignee" || tasks[0].CustomValues[0] != "Alice" {
+		t.Fatalf("unexpected custom columns: %#v / %#v", customCols, tasks[0].CustomValues)
+	}
+	if !tasks[0].IsCompleted() || !tasks[1].IsCancelled() {
+		t.Fatalf("expected mapped status values to be recognized")
+	}
+}
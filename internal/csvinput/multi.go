@@ -0,0 +1,119 @@
+package csvinput
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"ganttgen/internal/model"
+)
+
+// ReadFiles parses several CSV files into one schedule. Each file becomes a top-level
+// section named after the file, its own "#" sections are nested one level below, and task
+// keys are qualified with the file name so depends_on may reference other files as
+// "backend.csv:API実装" (the extension may be omitted unless another input has the same
+// name without its extension). Unqualified references resolve within the same file. A
+// single path behaves exactly like ReadWithOptions.
+func ReadFiles(paths []string, opts Options) ([]model.Task, []string, bool, error) {
+	if len(paths) == 1 {
+		return ReadWithOptions(paths[0], opts)
+	}
+
+	type input struct {
+		label  string
+		parsed parsedCSV
+	}
+	inputs := make([]input, 0, len(paths))
+	labels := make(map[string][]int, len(paths)*2)
+	for i, path := range paths {
+		label := filepath.Base(path)
+		if slices.ContainsFunc(inputs, func(in input) bool { return in.label == label }) {
+			return nil, nil, false, fmt.Errorf("duplicate input file name %q", label)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("open csv: %w", err)
+		}
+		parsed, err := parseBytes(raw, opts)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("%s: %w", label, err)
+		}
+		for j := range parsed.tasks {
+			parsed.tasks[j].Namespace = label
+			if parsed.tasks[j].IsHeading {
+				parsed.tasks[j].Level++
+			}
+		}
+		labels[label] = append(labels[label], i)
+		if stem := strings.TrimSuffix(label, filepath.Ext(label)); stem != label {
+			labels[stem] = append(labels[stem], i)
+		}
+		inputs = append(inputs, input{label: label, parsed: parsed})
+	}
+
+	indexes := make([]refIndex, len(inputs))
+	for i, in := range inputs {
+		indexes[i] = newRefIndex(in.parsed.tasks)
+	}
+
+	var customColumns []string
+	customPos := make(map[string]int)
+	hasProgressColumn := false
+	for _, in := range inputs {
+		for _, name := range in.parsed.customColumns {
+			if _, ok := customPos[name]; !ok {
+				customPos[name] = len(customColumns)
+				customColumns = append(customColumns, name)
+			}
+		}
+		hasProgressColumn = hasProgressColumn || in.parsed.hasProgressColumn
+	}
+
+	var tasks []model.Task
+	for i, in := range inputs {
+		tasks = append(tasks, model.Task{Name: in.label, Namespace: in.label, IsHeading: true})
+		for _, t := range in.parsed.tasks {
+			for j, dep := range t.DependsOn {
+				idx := indexes[i]
+				ref := dep
+				if file, local, ok := strings.Cut(dep, ":"); ok {
+					targets := labels[file]
+					if len(targets) > 1 {
+						names := make([]string, len(targets))
+						for k, target := range targets {
+							names[k] = inputs[target].label
+						}
+						return nil, nil, false, fmt.Errorf("task %q depends on ambiguous file name %q (use one of %s)", t.Key(), file, strings.Join(names, ", "))
+					}
+					if len(targets) == 1 {
+						idx = indexes[targets[0]]
+						ref = local
+					}
+				}
+				key, err := idx.lookup(ref)
+				if err != nil {
+					return nil, nil, false, fmt.Errorf("task %q %w", t.Key(), err)
+				}
+				t.DependsOn[j] = key
+			}
+			t.CustomValues = remapCustomValues(t.CustomValues, in.parsed.customColumns, customPos, len(customColumns))
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, customColumns, hasProgressColumn, nil
+}
+
+func remapCustomValues(values []string, names []string, positions map[string]int, count int) []string {
+	if count == 0 {
+		return nil
+	}
+	remapped := make([]string, count)
+	for i, name := range names {
+		if i < len(values) {
+			remapped[positions[name]] = values[i]
+		}
+	}
+	return remapped
+}
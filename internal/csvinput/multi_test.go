@@ -0,0 +1,99 @@
+package csvinput
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFilesMergesWithNamespacedDependencies(t *testing.T) {
+	dir := t.TempDir()
+	backend := `name,start,end,duration,depends_on,owner
+#API,,,,,
+API実装,2024-06-03,,3d,,Alice
+`
+	frontend := `name,start,end,duration,depends_on,priority
+画面実装,,,2d,backend.csv:API実装,High
+結合,,,1d,"画面実装;backend:API実装",
+`
+	if err := os.WriteFile(filepath.Join(dir, "backend.csv"), []byte(backend), 0o644); err != nil {
+		t.Fatalf("write backend: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "frontend.csv"), []byte(frontend), 0o644); err != nil {
+		t.Fatalf("write frontend: %v", err)
+	}
+
+	tasks, customCols, _, err := ReadFiles([]string{filepath.Join(dir, "backend.csv"), filepath.Join(dir, "frontend.csv")}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 6 {
+		t.Fatalf("expected 6 rows, got %d", len(tasks))
+	}
+	if !tasks[0].IsHeading || tasks[0].Name != "backend.csv" || tasks[0].Level != 0 {
+		t.Fatalf("expected file heading, got %#v", tasks[0])
+	}
+	if !tasks[1].IsHeading || tasks[1].Level != 1 {
+		t.Fatalf("expected nested section heading, got %#v", tasks[1])
+	}
+	if got := tasks[4].DependsOn; len(got) != 1 || got[0] != "backend.csv:API実装" {
+		t.Fatalf("unexpected cross-file depends_on: %#v", got)
+	}
+	if got := tasks[5].DependsOn; len(got) != 2 || got[0] != "frontend.csv:画面実装" || got[1] != "backend.csv:API実装" {
+		t.Fatalf("unexpected depends_on: %#v", got)
+	}
+	if len(customCols) != 2 || customCols[0] != "owner" || customCols[1] != "priority" {
+		t.Fatalf("unexpected custom columns: %#v", customCols)
+	}
+	if got := tasks[4].CustomValues; len(got) != 2 || got[0] != "" || got[1] != "High" {
+		t.Fatalf("unexpected remapped custom values: %#v", got)
+	}
+}
+
+func TestReadFilesKeepsUnqualifiedReferencesLocal(t *testing.T) {
+	dir := t.TempDir()
+	a := "name,start,end,duration,depends_on\nBuild,2024-06-03,,1d,\n"
+	b := "name,start,end,duration,depends_on\nTest,,,1d,Build\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.csv"), []byte(a), 0o644); err != nil {
+		t.Fatalf("write a: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.csv"), []byte(b), 0o644); err != nil {
+		t.Fatalf("write b: %v", err)
+	}
+
+	_, _, _, err := ReadFiles([]string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")}, Options{})
+	if err == nil || !strings.Contains(err.Error(), "unknown task") {
+		t.Fatalf("expected unknown task error for unqualified cross-file reference, got %v", err)
+	}
+}
+
+func TestReadFilesRejectsAmbiguousFileNames(t *testing.T) {
+	dir := t.TempDir()
+	csv := "name,start,end,duration,depends_on\nAPI実装,2024-06-03,,1d,\n"
+	tsv := "name\tstart\tend\tduration\tdepends_on\nAPI実装\t2024-06-03\t\t2d\t\n"
+	frontend := "name,start,end,duration,depends_on\n画面実装,,,1d,backend:API実装\n"
+	for name, content := range map[string]string{"backend.csv": csv, "backend.tsv": tsv, "frontend.csv": frontend} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	paths := []string{filepath.Join(dir, "backend.csv"), filepath.Join(dir, "backend.tsv"), filepath.Join(dir, "frontend.csv")}
+
+	_, _, _, err := ReadFiles(paths, Options{})
+	if err == nil || !strings.Contains(err.Error(), `ambiguous file name "backend" (use one of backend.csv, backend.tsv)`) {
+		t.Fatalf("expected ambiguous file name error, got %v", err)
+	}
+
+	frontend = "name,start,end,duration,depends_on\n画面実装,,,1d,backend.tsv:API実装\n"
+	if err := os.WriteFile(paths[2], []byte(frontend), 0o644); err != nil {
+		t.Fatalf("write frontend.csv: %v", err)
+	}
+	tasks, _, _, err := ReadFiles(paths, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := tasks[len(tasks)-1].DependsOn; len(got) != 1 || got[0] != "backend.tsv:API実装" {
+		t.Fatalf("unexpected depends_on: %#v", got)
+	}
+}
@@ -7,14 +7,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
-	"golang.org/x/text/encoding/japanese"
-	"golang.org/x/text/transform"
-
 	"ganttgen/internal/calendar"
 	"ganttgen/internal/model"
 )
@@ -22,6 +20,7 @@ import (
 var (
 	requiredColumns = []string{"name", "start", "end", "duration", "depends_on"}
 	columnAliases   = map[string]string{
+		"タスクid":    "id",
 		"タスク名":     "name",
 		"開始":       "start",
 		"終了":       "end",
@@ -33,11 +32,18 @@ var (
 		"進捗":       "progress",
 		"状態":       "status",
 		"備考":       "notes",
+		"繰り返し":     "repeat",
+		"中断":       "pause",
 		"notes":    "notes",
 		"progress": "progress",
 		"status":   "status",
+		"repeat":   "repeat",
+		"pause":    "pause",
+		"計算開始":     "computed_start",
+		"計算終了":     "computed_end",
 	}
 	knownColumns = map[string]struct{}{
+		"id":              {},
 		"name":            {},
 		"start":           {},
 		"end":             {},
@@ -49,6 +55,11 @@ var (
 		"progress":        {},
 		"status":          {},
 		"notes":           {},
+		"repeat":          {},
+		"pause":           {},
+		// Written by Resolve and ignored on input.
+		"computed_start": {},
+		"computed_end":   {},
 	}
 	dateLayouts = []string{
 		"2006-01-02", // zero-padded dash
@@ -63,32 +74,97 @@ type customColumn struct {
 	Index int
 }
 
+// Options controls how CSV input is interpreted.
+type Options struct {
+	// Today is the date "today" resolves to. The zero value means the current date.
+	Today time.Time
+	// Anchors maps anchor names to date expressions usable as "@name" in date columns.
+	// The "project" anchor is the base for bare offsets such as "+2w".
+	// Anchors given here take precedence over anchor rows in the CSV.
+	Anchors map[string]string
+	// Encoding forces the input encoding (e.g. "utf-8", "shift_jis", "euc-jp", "utf-16le").
+	// Empty or "auto" detects it from the BOM and header line.
+	Encoding string
+	// Delimiter forces the field delimiter. Zero sniffs it from the header line.
+	Delimiter rune
+	// Columns maps canonical columns (or custom column labels) to extra header names,
+	// checked before the built-in English/Japanese aliases.
+	Columns map[string]StringList
+}
+
+type csvRecord struct {
+	fields []string
+	row    int
+}
+
 // Read parses the CSV file and returns tasks with their raw attributes.
 func Read(path string) ([]model.Task, []string, bool, error) {
+	return ReadWithOptions(path, Options{})
+}
+
+// ReadWithOptions parses the CSV file like Read using the given options.
+func ReadWithOptions(path string, opts Options) ([]model.Task, []string, bool, error) {
 	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, nil, false, fmt.Errorf("open csv: %w", err)
 	}
+	return readBytes(raw, opts)
+}
+
+// ReadFrom parses CSV data from a stream such as stdin. The whole stream is buffered so
+// encoding and delimiter detection work the same way as for files.
+func ReadFrom(r io.Reader, opts Options) ([]model.Task, []string, bool, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("read csv: %w", err)
+	}
+	return readBytes(raw, opts)
+}
 
-	decoded, err := decodeCSVBytes(raw)
+func readBytes(raw []byte, opts Options) ([]model.Task, []string, bool, error) {
+	parsed, err := parseBytes(raw, opts)
 	if err != nil {
 		return nil, nil, false, err
 	}
+	if err := resolveDependencies(parsed.tasks); err != nil {
+		return nil, nil, false, err
+	}
+	return parsed.tasks, parsed.customColumns, parsed.hasProgressColumn, nil
+}
+
+// parsedCSV is a single parsed input whose depends_on references are not resolved yet.
+type parsedCSV struct {
+	tasks             []model.Task
+	customColumns     []string
+	hasProgressColumn bool
+	// rowKeys lists the keys of the tasks read from each row, by row number.
+	rowKeys map[int][]string
+}
+
+func parseBytes(raw []byte, opts Options) (parsedCSV, error) {
+	decoded, err := decodeCSVBytes(raw, opts.Encoding)
+	if err != nil {
+		return parsedCSV{}, err
+	}
 
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(decoded)
+	}
 	reader := csv.NewReader(bytes.NewReader(decoded))
+	reader.Comma = delimiter
 	header, err := reader.Read()
 	if err != nil {
-		return nil, nil, false, fmt.Errorf("read header: %w", err)
+		return parsedCSV{}, fmt.Errorf("read header: %w", err)
 	}
 
-	colIndex, customCols, err := mapColumns(header)
+	colIndex, customCols, err := mapColumns(header, headerAliases(opts.Columns))
 	if err != nil {
-		return nil, nil, false, err
+		return parsedCSV{}, err
 	}
 	_, hasProgressColumn := colIndex["progress"]
 
-	var tasks []model.Task
-	nameSet := make(map[string]struct{})
+	var records []csvRecord
 	row := 2 // 1-based row number, header is 1
 	for {
 		record, err := reader.Read()
@@ -97,52 +173,104 @@ func Read(path string) ([]model.Task, []string, bool, error) {
 		}
 		if err != nil {
 			if errors.Is(err, csv.ErrFieldCount) {
-				return nil, nil, false, fmt.Errorf("row %d: inconsistent field count", row)
+				return parsedCSV{}, fmt.Errorf("row %d: inconsistent field count", row)
 			}
-			return nil, nil, false, fmt.Errorf("row %d: %w", row, err)
+			return parsedCSV{}, fmt.Errorf("row %d: %w", row, err)
+		}
+		if !recordAllEmpty(record) {
+			records = append(records, csvRecord{fields: record, row: row})
 		}
+		row++
+	}
 
-		if recordAllEmpty(record) {
-			row++
+	// Anchor rows ("@name" in the name column) may be referenced from any row, so collect them first.
+	dates := newDateContext(opts)
+	for _, rec := range records {
+		name := fieldValue(rec.fields, colIndex, "name")
+		if !strings.HasPrefix(name, "@") {
 			continue
 		}
+		expr := fieldValue(rec.fields, colIndex, "start")
+		if expr == "" {
+			return parsedCSV{}, fmt.Errorf("row %d: anchor %s requires a date in start", rec.row, name)
+		}
+		dates.define(name, expr, fmt.Sprintf("row %d", rec.row))
+	}
 
-		task, err := parseRecord(record, colIndex, customCols, row)
+	parser := &rowParser{
+		col:        colIndex,
+		customCols: customCols,
+		dates:      dates,
+		delimiter:  delimiter,
+	}
+	var tasks []model.Task
+	rowKeys := make(map[int][]string)
+	keySet := make(map[string]struct{})
+	sectionNames := make(map[string]struct{})
+	for _, rec := range records {
+		row := rec.row
+		if strings.HasPrefix(fieldValue(rec.fields, colIndex, "name"), "@") {
+			continue
+		}
+		task, err := parser.parseRecord(rec.fields, row)
 		if err != nil {
-			return nil, nil, false, err
+			return parsedCSV{}, err
+		}
+		expanded, err := parser.expandRepeat(task, fieldValue(rec.fields, colIndex, "repeat"), row)
+		if err != nil {
+			return parsedCSV{}, err
 		}
 		if task.IsHeading {
+			// Names only need to be unique within a section; IDs stay globally unique.
+			sectionNames = make(map[string]struct{})
 			tasks = append(tasks, task)
-			row++
 			continue
 		}
-		if _, exists := nameSet[task.Name]; exists {
-			return nil, nil, false, fmt.Errorf("row %d: duplicate task name %q", row, task.Name)
+		for _, task := range expanded {
+			if _, exists := keySet[task.Key()]; exists {
+				if task.ID != "" {
+					return parsedCSV{}, fmt.Errorf("row %d: duplicate task id %q", row, task.ID)
+				}
+				return parsedCSV{}, fmt.Errorf("row %d: duplicate task name %q", row, task.Name)
+			}
+			if _, exists := sectionNames[task.Name]; exists {
+				return parsedCSV{}, fmt.Errorf("row %d: duplicate task name %q in the same section", row, task.Name)
+			}
+			keySet[task.Key()] = struct{}{}
+			sectionNames[task.Name] = struct{}{}
+			rowKeys[row] = append(rowKeys[row], task.Key())
+			tasks = append(tasks, task)
 		}
-		nameSet[task.Name] = struct{}{}
-		tasks = append(tasks, task)
-		row++
-	}
-
-	if err := validateDependencies(tasks); err != nil {
-		return nil, nil, false, err
 	}
 
-	return tasks, customColumnNames(customCols), hasProgressColumn, nil
+	return parsedCSV{
+		tasks:             tasks,
+		customColumns:     customColumnNames(customCols),
+		hasProgressColumn: hasProgressColumn,
+		rowKeys:           rowKeys,
+	}, nil
 }
 
-func mapColumns(header []string) (map[string]int, []customColumn, error) {
+func mapColumns(header []string, userAliases map[string]string) (map[string]int, []customColumn, error) {
 	mapped := make(map[string]int)
 	var customCols []customColumn
 	seenCustom := make(map[string]struct{})
 	for idx, col := range header {
-		key := strings.ToLower(strings.TrimSpace(col))
-		if canonical, ok := columnAliases[key]; ok {
+		trimmed := strings.TrimSpace(col)
+		key := strings.ToLower(trimmed)
+		if target, ok := userAliases[key]; ok {
+			if _, known := knownColumns[target]; known {
+				key = target
+			} else {
+				// A non-canonical target renames the custom column.
+				trimmed = target
+				key = strings.ToLower(target)
+			}
+		} else if canonical, ok := columnAliases[key]; ok {
 			key = canonical
 		}
 		mapped[key] = idx
 		if _, ok := knownColumns[key]; !ok {
-			trimmed := strings.TrimSpace(col)
 			if trimmed != "" {
 				if _, seen := seenCustom[trimmed]; !seen {
 					seenCustom[trimmed] = struct{}{}
@@ -159,19 +287,55 @@ func mapColumns(header []string) (map[string]int, []customColumn, error) {
 	return mapped, customCols, nil
 }
 
-func parseRecord(record []string, col map[string]int, customCols []customColumn, row int) (model.Task, error) {
+func fieldValue(record []string, col map[string]int, key string) string {
+	if idx, ok := col[key]; ok && idx < len(record) {
+		return strings.TrimSpace(record[idx])
+	}
+	return ""
+}
+
+// EscapeName returns a task name as written in the name column: a name starting with "#"
+// or "@", which would make a heading or an anchor row, gets a backslash in front.
+func EscapeName(name string) string {
+	if strings.HasPrefix(name, "#") || strings.HasPrefix(name, "@") || strings.HasPrefix(name, `\`) {
+		return `\` + name
+	}
+	return name
+}
+
+// unescapeName strips the backslash that lets a task name start with "#" or "@" (or "\")
+// without making a heading or an anchor row.
+func unescapeName(name string) string {
+	if len(name) > 1 && name[0] == '\\' && strings.ContainsRune(`#@\`, rune(name[1])) {
+		return name[1:]
+	}
+	return name
+}
+
+// rowParser holds the per-file state needed to turn a record into a task.
+type rowParser struct {
+	col        map[string]int
+	customCols []customColumn
+	dates      *dateContext
+	delimiter  rune
+}
+
+func (p *rowParser) parseRecord(record []string, row int) (model.Task, error) {
 	get := func(key string) string {
-		if idx, ok := col[key]; ok && idx < len(record) {
-			return strings.TrimSpace(record[idx])
-		}
-		return ""
+		return fieldValue(record, p.col, key)
 	}
+	dates := p.dates
 
-	customValues := makeCustomValues(record, customCols)
+	customValues := makeCustomValues(record, p.customCols)
+	id := get("id")
 	name := get("name")
-	statusStr := get("status")
+	statusStr, err := canonicalStatus(get("status"))
+	if err != nil {
+		return model.Task{}, fmt.Errorf("row %d: %w", row, err)
+	}
 	if strings.HasPrefix(name, "#") {
 		return model.Task{
+			ID:           id,
 			Name:         strings.TrimSpace(strings.TrimPrefix(name, "#")),
 			IsHeading:    true,
 			Status:       statusStr,
@@ -179,6 +343,7 @@ func parseRecord(record []string, col map[string]int, customCols []customColumn,
 			CustomValues: customValues,
 		}, nil
 	}
+	name = unescapeName(name)
 	startStr := get("start")
 	endStr := get("end")
 	durationStr := get("duration")
@@ -188,10 +353,15 @@ func parseRecord(record []string, col map[string]int, customCols []customColumn,
 	actualDurationStr := get("actual_duration")
 	progressStr := get("progress")
 	notesStr := get("notes")
+	pauseStr := get("pause")
+	if get("repeat") != "" && durationStr == "" && endStr == "" {
+		// Each occurrence of a recurring task lasts one day unless a duration is given.
+		durationStr = "1d"
+	}
 
 	// Name only (no scheduling/depends/actual) -> display-only row (notes allowed).
-	if name != "" && startStr == "" && endStr == "" && durationStr == "" && dependsStr == "" && actualStartStr == "" && actualEndStr == "" && actualDurationStr == "" {
-		return model.Task{Name: name, DisplayOnly: true, Notes: notesStr, CustomValues: customValues}, nil
+	if name != "" && startStr == "" && endStr == "" && durationStr == "" && dependsStr == "" && actualStartStr == "" && actualEndStr == "" && actualDurationStr == "" && pauseStr == "" {
+		return model.Task{ID: id, Name: name, DisplayOnly: true, Notes: notesStr, CustomValues: customValues}, nil
 	}
 
 	if name == "" {
@@ -199,8 +369,9 @@ func parseRecord(record []string, col map[string]int, customCols []customColumn,
 	}
 
 	task := model.Task{
+		ID:           id,
 		Name:         name,
-		DependsOn:    parseDepends(dependsStr),
+		DependsOn:    splitList(dependsStr, p.delimiter),
 		Notes:        notesStr,
 		Status:       statusStr,
 		CustomValues: customValues,
@@ -215,7 +386,7 @@ func parseRecord(record []string, col map[string]int, customCols []customColumn,
 	}
 
 	if startStr != "" {
-		parsed, err := parseDate(startStr)
+		parsed, err := dates.parse(startStr)
 		if err != nil {
 			return model.Task{}, fmt.Errorf("row %d: invalid start: %w", row, err)
 		}
@@ -223,14 +394,16 @@ func parseRecord(record []string, col map[string]int, customCols []customColumn,
 	}
 
 	if endStr != "" {
-		parsed, err := parseDate(endStr)
+		parsed, err := dates.parse(endStr)
 		if err != nil {
 			return model.Task{}, fmt.Errorf("row %d: invalid end: %w", row, err)
 		}
 		task.End = &parsed
 	}
 
-	if durationStr != "" {
+	if isMilestoneDuration(durationStr) {
+		task.Milestone = true
+	} else if durationStr != "" {
 		days, err := parseDuration(durationStr)
 		if err != nil {
 			return model.Task{}, fmt.Errorf("row %d: invalid duration: %w", row, err)
@@ -238,23 +411,31 @@ func parseRecord(record []string, col map[string]int, customCols []customColumn,
 		task.DurationDays = days
 	}
 
-	if task.End != nil && task.DurationDays > 0 {
+	if pauseStr != "" {
+		pauses, err := parseIntervals(pauseStr, dates, p.delimiter)
+		if err != nil {
+			return model.Task{}, fmt.Errorf("row %d: invalid pause: %w", row, err)
+		}
+		task.Pauses = pauses
+	}
+
+	if task.End != nil && (task.DurationDays > 0 || task.Milestone) {
 		return model.Task{}, fmt.Errorf("row %d: end and duration cannot both be set", row)
 	}
 	if task.End != nil && task.Start == nil && task.DurationDays == 0 {
 		return model.Task{}, fmt.Errorf("row %d: end cannot be set without start or duration", row)
 	}
-	if task.DurationDays == 0 && task.End == nil {
+	if task.DurationDays == 0 && task.End == nil && !task.Milestone {
 		return model.Task{}, fmt.Errorf("row %d: either duration or end must be provided", row)
 	}
-	if task.Start == nil && task.DurationDays > 0 && len(task.DependsOn) == 0 {
+	if task.Start == nil && (task.DurationDays > 0 || task.Milestone) && len(task.DependsOn) == 0 {
 		return model.Task{}, fmt.Errorf("row %d: duration-only task must depend on another task or define a start", row)
 	}
-	if task.Start == nil && task.End == nil && task.DurationDays == 0 {
+	if task.Start == nil && task.End == nil && task.DurationDays == 0 && !task.Milestone {
 		return model.Task{}, fmt.Errorf("row %d: task lacks scheduling information", row)
 	}
 
-	if err := parseActual(&task, actualStartStr, actualEndStr, actualDurationStr, row); err != nil {
+	if err := parseActual(&task, dates, actualStartStr, actualEndStr, actualDurationStr, p.delimiter, row); err != nil {
 		return model.Task{}, err
 	}
 
@@ -285,21 +466,41 @@ func customColumnNames(customCols []customColumn) []string {
 	return names
 }
 
-func parseDepends(raw string) []string {
+// canonicalStatus rejects statuses outside a configured vocabulary and rewrites
+// aliases to the defined status name.
+func canonicalStatus(status string) (string, error) {
+	if !model.KnownStatus(status) {
+		return "", fmt.Errorf("unknown status %q", status)
+	}
+	if len(model.Statuses()) == 0 {
+		return status, nil
+	}
+	if def, ok := model.LookupStatus(status); ok {
+		return def.Name, nil
+	}
+	return status, nil
+}
+
+// splitList splits a list cell (depends_on, pause) on ',' and ';'. A field delimiter other than ','
+// is never treated as a separator, so a quoted ';' in a ';'-delimited file stays part of the value.
+func splitList(raw string, delimiter rune) []string {
 	if raw == "" {
 		return nil
 	}
 	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		if r == delimiter && delimiter != ',' {
+			return false
+		}
 		return r == ',' || r == ';'
 	})
 
-	var deps []string
+	var values []string
 	for _, p := range parts {
 		if trimmed := strings.TrimSpace(p); trimmed != "" {
-			deps = append(deps, trimmed)
+			values = append(values, trimmed)
 		}
 	}
-	return deps
+	return values
 }
 
 func parseDate(raw string) (time.Time, error) {
@@ -311,6 +512,11 @@ func parseDate(raw string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or YYYY/MM/DD)", raw)
 }
 
+// isMilestoneDuration reports whether raw is the zero duration ("0d") marking a milestone.
+func isMilestoneDuration(raw string) bool {
+	return strings.EqualFold(raw, "0d")
+}
+
 func parseDuration(raw string) (int, error) {
 	if len(raw) < 2 {
 		return 0, errors.New("duration must be Nd (e.g. 5d)")
@@ -343,36 +549,96 @@ func parseProgress(raw string) (int, error) {
 	return value, nil
 }
 
-func validateDependencies(tasks []model.Task) error {
-	nameSet := make(map[string]struct{}, len(tasks))
-	for _, t := range tasks {
-		nameSet[t.Name] = struct{}{}
+// resolveDependencies rewrites each depends_on reference to the key of the task it points at.
+// References match task IDs first and fall back to names, which must then be unambiguous.
+func resolveDependencies(tasks []model.Task) error {
+	idx := newRefIndex(tasks)
+	for i := range tasks {
+		t := &tasks[i]
+		for j, dep := range t.DependsOn {
+			key, err := idx.lookup(dep)
+			if err != nil {
+				return fmt.Errorf("task %q %w", t.Key(), err)
+			}
+			t.DependsOn[j] = key
+		}
 	}
+	return nil
+}
+
+// refIndex looks up depends_on references among the tasks of one input.
+// A reference to a repeat row resolves to its last occurrence.
+type refIndex struct {
+	keys   map[string]string   // ID or name -> full key
+	byName map[string][]string // name -> full keys
+	series map[string]string   // repeat row ID or name -> key of the last occurrence
+}
 
+func newRefIndex(tasks []model.Task) refIndex {
+	idx := refIndex{
+		keys:   make(map[string]string, len(tasks)),
+		byName: make(map[string][]string, len(tasks)),
+		series: make(map[string]string),
+	}
 	for _, t := range tasks {
-		for _, dep := range t.DependsOn {
-			if _, ok := nameSet[dep]; !ok {
-				return fmt.Errorf("task %q depends on unknown task %q", t.Name, dep)
+		if t.IsHeading {
+			continue
+		}
+		local := t.ID
+		if local == "" {
+			local = t.Name
+		}
+		idx.keys[local] = t.Key()
+		idx.byName[t.Name] = append(idx.byName[t.Name], t.Key())
+		if occ := t.Occurrence; occ != nil {
+			idx.series[occ.SeriesName] = t.Key()
+			if occ.SeriesID != "" {
+				idx.series[occ.SeriesID] = t.Key()
 			}
 		}
 	}
-	return nil
+	return idx
+}
+
+func (idx refIndex) lookup(ref string) (string, error) {
+	if key, ok := idx.keys[ref]; ok {
+		return key, nil
+	}
+	if key, ok := idx.series[ref]; ok {
+		return key, nil
+	}
+	candidates := idx.byName[ref]
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("depends on unknown task %q", ref)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("depends on ambiguous task name %q (use one of ids %s)", ref, strings.Join(candidates, ", "))
+	}
 }
 
-func parseActual(task *model.Task, startStr, endStr, durationStr string, row int) error {
+func parseActual(task *model.Task, dates *dateContext, startStr, endStr, durationStr string, delimiter rune, row int) error {
 	if startStr == "" && endStr == "" && durationStr == "" {
 		return nil
 	}
 
 	if startStr != "" {
-		parsed, err := parseDate(startStr)
+		parsed, err := dates.parse(startStr)
 		if err != nil {
+			intervals, ivErr := parseIntervals(startStr, dates, delimiter)
+			if ivErr == nil {
+				return setActualIntervals(task, intervals, endStr, durationStr, row)
+			}
+			if strings.ContainsAny(startStr, ".~〜～;,") {
+				err = ivErr
+			}
 			return fmt.Errorf("row %d: invalid actual_start: %w", row, err)
 		}
 		task.ActualStart = ptrTime(calendar.NextWorkday(parsed))
 	}
 	if endStr != "" {
-		parsed, err := parseDate(endStr)
+		parsed, err := dates.parse(endStr)
 		if err != nil {
 			return fmt.Errorf("row %d: invalid actual_end: %w", row, err)
 		}
@@ -419,53 +685,99 @@ func parseActual(task *model.Task, startStr, endStr, durationStr string, row int
 	return nil
 }
 
-func ptrTime(t time.Time) *time.Time { return &t }
-
-func recordAllEmpty(record []string) bool {
-	for _, v := range record {
-		if strings.TrimSpace(v) != "" {
-			return false
+// setActualIntervals records several actual work periods given as an interval list in
+// actual_start. Each period is trimmed to workdays; periods must not overlap.
+func setActualIntervals(task *model.Task, intervals []model.Interval, endStr, durationStr string, row int) error {
+	if endStr != "" || durationStr != "" {
+		return fmt.Errorf("row %d: actual_end and actual_duration cannot be combined with actual intervals", row)
+	}
+	segments := make([]model.Interval, 0, len(intervals))
+	for _, iv := range intervals {
+		start := calendar.NextWorkday(iv.Start)
+		end := calendar.DateOnly(iv.End)
+		for !calendar.IsWorkday(end) && end.After(start) {
+			end = end.AddDate(0, 0, -1)
+		}
+		if end.Before(start) {
+			// A period of non-working days only moves to the next workday, like a single date.
+			end = start
 		}
+		segments = append(segments, model.Interval{Start: start, End: end})
 	}
-	return true
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Start.Before(segments[j].Start) })
+	for i := 1; i < len(segments); i++ {
+		if !segments[i].Start.After(segments[i-1].End) {
+			return fmt.Errorf("row %d: actual intervals overlap", row)
+		}
+	}
+	first, last := segments[0], segments[len(segments)-1]
+	task.ActualStart = ptrTime(first.Start)
+	task.ActualEnd = ptrTime(last.End)
+	task.ComputedActualStart = ptrTime(first.Start)
+	task.ComputedActualEnd = ptrTime(last.End)
+	if len(segments) > 1 {
+		task.ActualSegments = segments
+	}
+	return nil
 }
 
-type encodingKind int
-
-const (
-	encUTF8 encodingKind = iota
-	encShiftJIS
-)
-
-// decodeCSVBytes inspects the header to decide UTF-8 or Shift_JIS and returns UTF-8 bytes.
-func decodeCSVBytes(data []byte) ([]byte, error) {
-	enc := detectEncoding(data)
-	switch enc {
-	case encUTF8:
-		if !utf8.Valid(data) {
-			return nil, fmt.Errorf("csv is not valid utf-8")
-		}
-		return data, nil
-	case encShiftJIS:
-		reader := transform.NewReader(bytes.NewReader(data), japanese.ShiftJIS.NewDecoder())
-		decoded, err := io.ReadAll(reader)
-		if err != nil {
-			return nil, fmt.Errorf("decode Shift_JIS: %w", err)
+// sniffDelimiter picks the most frequent candidate delimiter outside quotes on the header line.
+// Ties and headers without any candidate fall back to ','.
+func sniffDelimiter(data []byte) rune {
+	candidates := []rune{',', ';', '\t', '|'}
+	counts := make(map[rune]int, len(candidates))
+	inQuotes := false
+	for _, r := range string(data) {
+		if r == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if inQuotes {
+			continue
+		}
+		if r == '\n' || r == '\r' {
+			break
+		}
+		counts[r]++
+	}
+	best := ','
+	for _, c := range candidates {
+		if counts[c] > counts[best] {
+			best = c
 		}
-		return decoded, nil
-	default:
-		return nil, fmt.Errorf("unknown encoding")
 	}
+	return best
 }
 
-// detectEncoding uses the first line (header) as a sample and falls back to Shift_JIS if not valid UTF-8.
-func detectEncoding(data []byte) encodingKind {
-	sample := data
-	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
-		sample = data[:idx]
-	}
-	if utf8.Valid(sample) {
-		return encUTF8
+// ParseDelimiter converts a user supplied delimiter name ("tab", "\t", ";", ...) into a rune.
+// Empty or "auto" returns zero, meaning the delimiter is sniffed from the header.
+func ParseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "", "auto":
+		return 0, nil
+	case "tab", "\\t", "\t", "tsv":
+		return '\t', nil
+	case "comma", "csv":
+		return ',', nil
+	case "semicolon":
+		return ';', nil
+	case "pipe":
+		return '|', nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\n' || runes[0] == '\r' || runes[0] == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q (use a single character, tab or auto)", value)
+	}
+	return runes[0], nil
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
+
+func recordAllEmpty(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
 	}
-	return encShiftJIS
+	return true
 }
@@ -1,6 +1,7 @@
 package csvinput
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,8 @@ import (
 
 	"golang.org/x/text/encoding/japanese"
 	"golang.org/x/text/transform"
+
+	"ganttgen/internal/model"
 )
 
 func TestReadValidCSV(t *testing.T) {
@@ -232,3 +235,353 @@ Task,2024-06-03,,1d,,Alice,High
 		t.Fatalf("unexpected custom values: %#v", tasks[0].CustomValues)
 	}
 }
+
+func TestReadResolvesDependenciesByID(t *testing.T) {
+	content := `id,name,start,end,duration,depends_on
+,#Backend,,,,
+be-1,Implement,2024-06-03,,2d,
+,#Frontend,,,,
+fe-1,Implement,,,3d,be-1
+fe-2,Review,,,1d,fe-1
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ids.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(tasks))
+	}
+	if tasks[3].ID != "fe-1" || tasks[3].Name != "Implement" {
+		t.Fatalf("unexpected task: %#v", tasks[3])
+	}
+	if got := tasks[3].DependsOn; len(got) != 1 || got[0] != "be-1" {
+		t.Fatalf("unexpected depends_on: %#v", got)
+	}
+}
+
+func TestReadResolvesUniqueNameToID(t *testing.T) {
+	content := `id,name,start,end,duration,depends_on
+a,Design,2024-06-03,,2d,
+b,Build,,,3d,Design
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "names.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := tasks[1].DependsOn; len(got) != 1 || got[0] != "a" {
+		t.Fatalf("expected name to resolve to id, got %#v", got)
+	}
+}
+
+func TestReadRejectsAmbiguousNameReference(t *testing.T) {
+	content := `id,name,start,end,duration,depends_on
+,#A,,,,
+a1,Implement,2024-06-03,,2d,
+,#B,,,,
+b1,Implement,2024-06-03,,2d,
+c1,Release,,,1d,Implement
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ambiguous.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	_, _, _, err := Read(path)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("expected ambiguous reference error, got %v", err)
+	}
+}
+
+func TestReadDuplicateID(t *testing.T) {
+	content := `id,name,start,end,duration,depends_on
+x,A,2024-06-03,,1d,
+x,B,2024-06-04,,1d,
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dupid.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	_, _, _, err := Read(path)
+	if err == nil || !strings.Contains(err.Error(), "duplicate task id") {
+		t.Fatalf("expected duplicate id error, got %v", err)
+	}
+}
+
+func TestReadEscapedNamesAreTasks(t *testing.T) {
+	content := `name,start,end,duration,depends_on
+\@review,2026-04-01,,1d,
+\#1 Kickoff,2026-04-02,,1d,
+\\share,2026-04-03,,1d,
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "escaped.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"@review", "#1 Kickoff", `\share`}
+	if len(tasks) != len(want) {
+		t.Fatalf("expected %d tasks, got %d", len(want), len(tasks))
+	}
+	for i, task := range tasks {
+		if task.IsHeading || task.Name != want[i] {
+			t.Fatalf("task %d read as %q (heading %v), want %q", i, task.Name, task.IsHeading, want[i])
+		}
+	}
+}
+
+func TestReadResolvesAnchorRows(t *testing.T) {
+	content := `name,start,end,duration,depends_on,actual_start
+@project,2026-04-01,,,,
+@kickoff,+1w,,,,
+Prepare,@kickoff,,2d,,@kickoff+1d
+Review,4/20,,1d,,
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "anchors.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected anchor rows to be skipped, got %d tasks", len(tasks))
+	}
+	if tasks[0].Start == nil || tasks[0].Start.Format("2006-01-02") != "2026-04-08" {
+		t.Fatalf("unexpected start for Prepare: %v", tasks[0].Start)
+	}
+	if tasks[0].ComputedActualStart == nil || tasks[0].ComputedActualStart.Format("2006-01-02") != "2026-04-09" {
+		t.Fatalf("unexpected actual start for Prepare: %v", tasks[0].ComputedActualStart)
+	}
+	if tasks[1].Start == nil || tasks[1].Start.Format("2006-01-02") != "2026-04-20" {
+		t.Fatalf("unexpected start for Review: %v", tasks[1].Start)
+	}
+}
+
+func TestReadOptionAnchorsOverrideRows(t *testing.T) {
+	content := `name,start,end,duration,depends_on
+@project,2026-04-01,,,
+Prepare,+0d,,2d,
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "override.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := ReadWithOptions(path, Options{Anchors: map[string]string{"project": "2026-06-01"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tasks[0].Start == nil || tasks[0].Start.Format("2006-01-02") != "2026-06-01" {
+		t.Fatalf("expected option anchor to win, got %v", tasks[0].Start)
+	}
+}
+
+func TestReadValidatesConfiguredStatuses(t *testing.T) {
+	if err := model.SetStatuses([]model.StatusDef{
+		{Name: "進行中", Aliases: []string{"wip"}, Semantic: model.SemanticInProgress},
+	}); err != nil {
+		t.Fatalf("set statuses: %v", err)
+	}
+	t.Cleanup(func() { model.SetStatuses(nil) })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "statuses.csv")
+	content := `name,status,start,end,duration,depends_on
+Build,WIP,2026-04-01,,2d,
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tasks[0].Status != "進行中" {
+		t.Fatalf("expected alias to be canonicalized, got %q", tasks[0].Status)
+	}
+
+	content = `name,status,start,end,duration,depends_on
+Build,レビュー待ち,2026-04-01,,2d,
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	if _, _, _, err := Read(path); err == nil || !strings.Contains(err.Error(), `row 2: unknown status "レビュー待ち"`) {
+		t.Fatalf("expected unknown status error, got %v", err)
+	}
+
+	content = `name,status,start,end,duration,depends_on
+#Backend,進行ちゅう,,,,
+Build,wip,2026-04-01,,2d,
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	if _, _, _, err := Read(path); err == nil || !strings.Contains(err.Error(), `row 2: unknown status "進行ちゅう"`) {
+		t.Fatalf("expected unknown status error for the heading, got %v", err)
+	}
+}
+
+func TestReadSniffsSemicolonDelimiter(t *testing.T) {
+	content := "name;start;end;duration;depends_on\n" +
+		"A;2024-06-03;;2d;\n" +
+		"B;;;3d;A\n" +
+		"C;2024-06-03;;1d;\n" +
+		"D;;;1d;A,C\n"
+	dir := t.TempDir()
+	path := filepath.Join(dir, "semicolon.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %d", len(tasks))
+	}
+	if got := tasks[3].DependsOn; len(got) != 2 || got[0] != "A" || got[1] != "C" {
+		t.Fatalf("unexpected depends_on: %#v", got)
+	}
+}
+
+func TestReadKeepsQuotedDelimiterInDependsName(t *testing.T) {
+	content := "name;start;end;duration;depends_on\n" +
+		"\"Plan;Review\";2024-06-03;;2d;\n" +
+		"Build;;;3d;\"Plan;Review\"\n"
+	dir := t.TempDir()
+	path := filepath.Join(dir, "quoted.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	tasks, _, _, err := Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := tasks[1].DependsOn; len(got) != 1 || got[0] != "Plan;Review" {
+		t.Fatalf("unexpected depends_on: %#v", got)
+	}
+}
+
+func TestReadTSVWithExplicitDelimiter(t *testing.T) {
+	content := "name\tstart\tend\tduration\tdepends_on\n" +
+		"A\t2024-06-03\t\t2d\t\n" +
+		"B\t\t\t1d\tA\n"
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.tsv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	delimiter, err := ParseDelimiter("tab")
+	if err != nil {
+		t.Fatalf("parse delimiter: %v", err)
+	}
+	tasks, _, _, err := ReadWithOptions(path, Options{Delimiter: delimiter})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].DependsOn[0] != "A" {
+		t.Fatalf("unexpected tasks: %#v", tasks)
+	}
+	if sniffDelimiter([]byte(content)) != '\t' {
+		t.Fatalf("expected tab to be sniffed")
+	}
+}
+
+func TestReadFromStreamDetectsEncoding(t *testing.T) {
+	content := "タスク名;開始;終了;期間;依存\n計画;2024-06-03;;2d;\n"
+	encoded, _, err := transform.String(japanese.ShiftJIS.NewEncoder(), content)
+	if err != nil {
+		t.Fatalf("encode to Shift_JIS: %v", err)
+	}
+
+	tasks, _, _, err := ReadFrom(bytes.NewReader([]byte(encoded)), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Name != "計画" {
+		t.Fatalf("unexpected tasks: %#v", tasks)
+	}
+}
+
+func TestReadActualIntervalList(t *testing.T) {
+	content := `name,start,end,duration,depends_on,actual_start,actual_end
+Build,2026-01-05,,8d,,"2026/1/5-1/7; 2026/1/10-2026/1/14",
+Test,2026-01-05,,2d,,2026-01-05..2026-01-06,2026-01-07
+`
+	_, _, _, err := ReadFrom(strings.NewReader(content), Options{})
+	if err == nil || !strings.Contains(err.Error(), "row 3") {
+		t.Fatalf("expected error for actual_end with intervals on row 3, got %v", err)
+	}
+
+	content = strings.Replace(content, "2026-01-05..2026-01-06,2026-01-07", "2026-01-05..2026-01-06,", 1)
+	tasks, _, _, err := ReadFrom(strings.NewReader(content), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	build := tasks[0]
+	if len(build.ActualSegments) != 2 {
+		t.Fatalf("expected 2 actual segments, got %#v", build.ActualSegments)
+	}
+	// 2026-01-10 is a Saturday, so the second period starts on Monday.
+	second := build.ActualSegments[1]
+	if second.Start.Format("2006-01-02") != "2026-01-12" || second.End.Format("2006-01-02") != "2026-01-14" {
+		t.Fatalf("unexpected second segment: %v - %v", second.Start, second.End)
+	}
+	if build.ComputedActualStart.Format("2006-01-02") != "2026-01-05" || build.ComputedActualEnd.Format("2006-01-02") != "2026-01-14" {
+		t.Fatalf("unexpected actual range: %v - %v", build.ComputedActualStart, build.ComputedActualEnd)
+	}
+	if test := tasks[1]; test.ActualSegments != nil || test.ComputedActualEnd.Format("2006-01-02") != "2026-01-06" {
+		t.Fatalf("single interval should set the actual range only: %#v", test)
+	}
+}
+
+func TestReadZeroDurationMarksMilestone(t *testing.T) {
+	content := `name,start,end,duration,depends_on
+Build,2026-01-05,,3d,
+Release,,,0d,Build
+`
+	tasks, _, _, err := ReadFrom(strings.NewReader(content), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tasks[0].Milestone || !tasks[1].Milestone || tasks[1].DurationDays != 0 {
+		t.Fatalf("expected only Release to be a milestone: %#v", tasks)
+	}
+
+	content = "name,start,end,duration,depends_on\nRelease,2026-01-05,2026-01-06,0d,\n"
+	if _, _, _, err := ReadFrom(strings.NewReader(content), Options{}); err == nil || !strings.Contains(err.Error(), "end and duration") {
+		t.Fatalf("expected end/duration conflict, got %v", err)
+	}
+	content = "name,start,end,duration,depends_on\nRelease,,,0d,\n"
+	if _, _, _, err := ReadFrom(strings.NewReader(content), Options{}); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("expected error for a milestone without start or dependency, got %v", err)
+	}
+}
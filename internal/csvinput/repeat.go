@@ -0,0 +1,358 @@
+package csvinput
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// maxOccurrences bounds how many tasks a single repeat row may expand to.
+const maxOccurrences = 1000
+
+var (
+	repeatUntil   = regexp.MustCompile(`(?i)^(.*?)\s+until\s+(.+)$`)
+	repeatCount   = regexp.MustCompile(`(?i)^(.*?)\s*(?:(?:\bx|×)\s*(\d+)|(\d+)\s*回)$`)
+	repeatOnWord  = regexp.MustCompile(`(?i)^(.*?)\s+on\s+(.+)$`)
+	repeatEvery   = regexp.MustCompile(`(?i)^every\s*(\d+)\s*(d|days?|w|weeks?|m|months?)$`)
+	repeatEveryJa = regexp.MustCompile(`^(\d+)\s*(日|週|か月|ヶ月|カ月)ごと$`)
+	repeatNth     = regexp.MustCompile(`(?i)^(第\s*)?(\d+)\s*(?:st|nd|rd|th)?\s*(.*)$`)
+	repeatLast    = regexp.MustCompile(`(?i)^(?:last|最終)\s*(.+)$`)
+)
+
+// repeatUnit is the step of a repeat rule. Daily steps count workdays only.
+type repeatUnit int
+
+const (
+	unitWorkday repeatUnit = iota
+	unitWeek
+	unitMonth
+)
+
+var namedFrequencies = map[string]struct {
+	unit  repeatUnit
+	every int
+}{
+	"daily":    {unitWorkday, 1},
+	"毎日":       {unitWorkday, 1},
+	"weekly":   {unitWeek, 1},
+	"毎週":       {unitWeek, 1},
+	"biweekly": {unitWeek, 2},
+	"隔週":       {unitWeek, 2},
+	"monthly":  {unitMonth, 1},
+	"毎月":       {unitMonth, 1},
+}
+
+// repeatOn selects the day within a week or month ("on mon", "on 1st workday", "on 15").
+type repeatOn struct {
+	kind    onKind
+	nth     int // day of month, or 1-based position; -1 means the last one
+	weekday time.Weekday
+}
+
+type onKind int
+
+const (
+	onNone onKind = iota
+	onDay
+	onWeekday
+	onWorkday
+)
+
+// repeatRule is a parsed repeat cell, for example "weekly x8",
+// "every 2w until 2026-03-31" or "monthly on 1st workday x6".
+type repeatRule struct {
+	unit  repeatUnit
+	every int
+	on    repeatOn
+	count int
+	until *time.Time
+}
+
+func parseRepeat(raw string, dates *dateContext) (repeatRule, error) {
+	rest := strings.TrimSpace(raw)
+	var rule repeatRule
+	if m := repeatUntil.FindStringSubmatch(rest); m != nil {
+		until, err := dates.parse(strings.TrimSpace(m[2]))
+		if err != nil {
+			return repeatRule{}, fmt.Errorf("invalid until: %w", err)
+		}
+		rule.until = &until
+		rest = strings.TrimSpace(m[1])
+	}
+	if m := repeatCount.FindStringSubmatch(rest); m != nil {
+		digits := m[2]
+		if digits == "" {
+			digits = m[3]
+		}
+		count, err := strconv.Atoi(digits)
+		if err != nil || count <= 0 {
+			return repeatRule{}, fmt.Errorf("invalid repeat count %q", digits)
+		}
+		rule.count = count
+		rest = strings.TrimSpace(m[1])
+	}
+	if rule.count == 0 && rule.until == nil {
+		return repeatRule{}, errors.New("repeat needs a count (x8) or an end date (until 2026-03-31)")
+	}
+	if rule.count > maxOccurrences {
+		return repeatRule{}, fmt.Errorf("repeat count %d exceeds %d", rule.count, maxOccurrences)
+	}
+
+	freq, on := rest, ""
+	if m := repeatOnWord.FindStringSubmatch(rest); m != nil {
+		freq, on = strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+	} else if head, tail, ok := strings.Cut(rest, " "); ok && !repeatEvery.MatchString(rest) {
+		// Japanese rules put the day right after the frequency: "毎月 第1営業日".
+		freq, on = head, strings.TrimSpace(tail)
+	}
+	if err := rule.parseFrequency(freq); err != nil {
+		return repeatRule{}, err
+	}
+	if on != "" {
+		parsed, err := parseRepeatOn(on)
+		if err != nil {
+			return repeatRule{}, err
+		}
+		rule.on = parsed
+	}
+	if err := rule.validateOn(); err != nil {
+		return repeatRule{}, err
+	}
+	return rule, nil
+}
+
+func (r *repeatRule) parseFrequency(freq string) error {
+	if named, ok := namedFrequencies[strings.ToLower(freq)]; ok {
+		r.unit, r.every = named.unit, named.every
+		return nil
+	}
+	var num, unit string
+	if m := repeatEvery.FindStringSubmatch(freq); m != nil {
+		num, unit = m[1], strings.ToLower(m[2][:1])
+	} else if m := repeatEveryJa.FindStringSubmatch(freq); m != nil {
+		num, unit = m[1], map[string]string{"日": "d", "週": "w"}[m[2]]
+		if unit == "" {
+			unit = "m"
+		}
+	} else {
+		return fmt.Errorf("unknown repeat frequency %q (use daily, weekly, biweekly, monthly or every Nd/Nw/Nm)", freq)
+	}
+	every, err := strconv.Atoi(num)
+	if err != nil || every <= 0 {
+		return fmt.Errorf("invalid repeat interval %q", freq)
+	}
+	r.every = every
+	switch unit {
+	case "d":
+		r.unit = unitWorkday
+	case "w":
+		r.unit = unitWeek
+	default:
+		r.unit = unitMonth
+	}
+	return nil
+}
+
+func parseRepeatOn(raw string) (repeatOn, error) {
+	lower := strings.ToLower(raw)
+	if m := repeatLast.FindStringSubmatch(lower); m != nil {
+		if isWorkdayWord(m[1]) {
+			return repeatOn{kind: onWorkday, nth: -1}, nil
+		}
+		if day, err := calendar.ParseWeekday(m[1]); err == nil {
+			return repeatOn{kind: onWeekday, nth: -1, weekday: day}, nil
+		}
+	}
+	if m := repeatNth.FindStringSubmatch(lower); m != nil {
+		nth, err := strconv.Atoi(m[2])
+		if err == nil && nth > 0 {
+			word := strings.TrimSpace(m[3])
+			switch {
+			case (word == "" || word == "日") && m[1] == "":
+				if nth > 31 {
+					return repeatOn{}, fmt.Errorf("invalid day of month %d", nth)
+				}
+				return repeatOn{kind: onDay, nth: nth}, nil
+			case isWorkdayWord(word):
+				return repeatOn{kind: onWorkday, nth: nth}, nil
+			}
+			if day, err := calendar.ParseWeekday(word); err == nil {
+				return repeatOn{kind: onWeekday, nth: nth, weekday: day}, nil
+			}
+		}
+	}
+	if day, err := calendar.ParseWeekday(lower); err == nil {
+		return repeatOn{kind: onWeekday, weekday: day}, nil
+	}
+	return repeatOn{}, fmt.Errorf("unknown repeat day %q (use e.g. mon, 15, 1st workday, last fri)", raw)
+}
+
+func isWorkdayWord(word string) bool {
+	switch strings.TrimSpace(word) {
+	case "workday", "business day", "営業日":
+		return true
+	}
+	return false
+}
+
+func (r repeatRule) validateOn() error {
+	switch r.unit {
+	case unitWorkday:
+		if r.on.kind != onNone {
+			return errors.New("daily repeats cannot choose a day")
+		}
+	case unitWeek:
+		if r.on.kind != onNone && (r.on.kind != onWeekday || r.on.nth != 0) {
+			return errors.New("weekly repeats can only choose a weekday (e.g. on mon)")
+		}
+	case unitMonth:
+		if r.on.kind == onWeekday && r.on.nth == 0 {
+			return errors.New("monthly repeats need a position for the weekday (e.g. on 1st mon, on last fri)")
+		}
+	}
+	return nil
+}
+
+// occurrences lists the start dates of the series beginning at start.
+func (r repeatRule) occurrences(start time.Time) ([]time.Time, error) {
+	start = calendar.DateOnly(start)
+	var dates []time.Time
+	add := func(d time.Time) (bool, error) {
+		if r.until != nil && d.After(*r.until) {
+			return false, nil
+		}
+		if len(dates) == maxOccurrences {
+			return false, fmt.Errorf("repeat expands to more than %d occurrences", maxOccurrences)
+		}
+		dates = append(dates, d)
+		return r.count == 0 || len(dates) < r.count, nil
+	}
+
+	switch r.unit {
+	case unitWorkday:
+		for d := calendar.NextWorkday(start); ; d = calendar.AddWorkdays(d, r.every) {
+			if more, err := add(d); !more || err != nil {
+				return dates, err
+			}
+		}
+	case unitWeek:
+		first := start
+		if r.on.kind == onWeekday {
+			for first.Weekday() != r.on.weekday {
+				first = first.AddDate(0, 0, 1)
+			}
+		}
+		for i := 0; ; i++ {
+			if more, err := add(first.AddDate(0, 0, 7*r.every*i)); !more || err != nil {
+				return dates, err
+			}
+		}
+	default:
+		for i := 0; ; i++ {
+			month := time.Date(start.Year(), start.Month()+time.Month(r.every*i), 1, 0, 0, 0, 0, start.Location())
+			d, ok := r.on.inMonth(month, start.Day())
+			if !ok || d.Before(start) {
+				if i > maxOccurrences {
+					return dates, errors.New("repeat never produces an occurrence")
+				}
+				continue
+			}
+			if more, err := add(d); !more || err != nil {
+				return dates, err
+			}
+		}
+	}
+}
+
+// inMonth returns the selected day of the month starting at first. Days past the end of
+// a short month are clamped to its last day; an nth workday or weekday that does not
+// exist in the month reports false.
+func (on repeatOn) inMonth(first time.Time, defaultDay int) (time.Time, bool) {
+	last := first.AddDate(0, 1, -1)
+	switch on.kind {
+	case onWorkday:
+		if on.nth < 0 {
+			d := last
+			for !calendar.IsWorkday(d) {
+				d = d.AddDate(0, 0, -1)
+			}
+			return d, d.Month() == first.Month()
+		}
+		d := calendar.AddWorkdays(first, on.nth-1)
+		return d, d.Month() == first.Month()
+	case onWeekday:
+		if on.nth < 0 {
+			d := last
+			for d.Weekday() != on.weekday {
+				d = d.AddDate(0, 0, -1)
+			}
+			return d, true
+		}
+		d := first
+		for d.Weekday() != on.weekday {
+			d = d.AddDate(0, 0, 1)
+		}
+		d = d.AddDate(0, 0, 7*(on.nth-1))
+		return d, d.Month() == first.Month()
+	}
+	day := defaultDay
+	if on.kind == onDay {
+		day = on.nth
+	}
+	if day > last.Day() {
+		day = last.Day()
+	}
+	return first.AddDate(0, 0, day-1), true
+}
+
+// expandRepeat replaces a row with a repeat rule by one task per occurrence. Each task
+// keeps the row's attributes, starts on its occurrence date and is named "Name #k"
+// (IDs become "ID#k"). Rows without a rule are returned unchanged.
+func (p *rowParser) expandRepeat(task model.Task, raw string, row int) ([]model.Task, error) {
+	if raw == "" {
+		return []model.Task{task}, nil
+	}
+	switch {
+	case task.IsHeading:
+		return nil, fmt.Errorf("row %d: repeat cannot be used on a section heading", row)
+	case task.Start == nil:
+		return nil, fmt.Errorf("row %d: repeat requires start", row)
+	case task.End != nil:
+		return nil, fmt.Errorf("row %d: repeat cannot be combined with end (use duration for each occurrence)", row)
+	case len(task.DependsOn) > 0:
+		return nil, fmt.Errorf("row %d: repeat cannot be combined with depends_on", row)
+	case task.ComputedActualStart != nil:
+		return nil, fmt.Errorf("row %d: repeat cannot be combined with actual dates", row)
+	}
+	rule, err := parseRepeat(raw, p.dates)
+	if err != nil {
+		return nil, fmt.Errorf("row %d: invalid repeat: %w", row, err)
+	}
+	dates, err := rule.occurrences(*task.Start)
+	if err != nil {
+		return nil, fmt.Errorf("row %d: invalid repeat: %w", row, err)
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("row %d: repeat has no occurrences before its end date", row)
+	}
+
+	tasks := make([]model.Task, len(dates))
+	for i, d := range dates {
+		inst := task
+		start := d
+		inst.Start = &start
+		inst.Name = fmt.Sprintf("%s #%d", task.Name, i+1)
+		if task.ID != "" {
+			inst.ID = fmt.Sprintf("%s#%d", task.ID, i+1)
+		}
+		inst.Occurrence = &model.Occurrence{SeriesID: task.ID, SeriesName: task.Name, Index: i + 1, Count: len(dates)}
+		tasks[i] = inst
+	}
+	return tasks, nil
+}
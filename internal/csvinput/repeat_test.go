@@ -0,0 +1,91 @@
+package csvinput
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRepeatOccurrences(t *testing.T) {
+	dates := newDateContext(Options{Today: time.Date(2026, time.January, 5, 0, 0, 0, 0, time.Local)})
+	cases := []struct {
+		rule  string
+		start string
+		want  []string
+	}{
+		{rule: "weekly x3", start: "2026-01-05", want: []string{"2026-01-05", "2026-01-12", "2026-01-19"}},
+		{rule: "every 2w until 2026-02-16", start: "2026-01-05", want: []string{"2026-01-05", "2026-01-19", "2026-02-02", "2026-02-16"}},
+		{rule: "weekly on fri x3", start: "2026-01-05", want: []string{"2026-01-09", "2026-01-16", "2026-01-23"}},
+		{rule: "毎日 3回", start: "2026-01-09", want: []string{"2026-01-09", "2026-01-12", "2026-01-13"}},
+		{rule: "monthly x3", start: "2026-01-31", want: []string{"2026-01-31", "2026-02-28", "2026-03-31"}},
+		{rule: "monthly on 1st workday x3", start: "2026-01-05", want: []string{"2026-02-02", "2026-03-02", "2026-04-01"}},
+		{rule: "毎月 最終営業日 ×2", start: "2026-01-05", want: []string{"2026-01-30", "2026-02-27"}},
+		{rule: "monthly on 2nd tue x2", start: "2026-01-05", want: []string{"2026-01-13", "2026-02-10"}},
+		{rule: "2か月ごと on 15 x2", start: "2026-01-05", want: []string{"2026-01-15", "2026-03-15"}},
+	}
+	for _, tc := range cases {
+		rule, err := parseRepeat(tc.rule, dates)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.rule, err)
+		}
+		start, _ := parseDate(tc.start)
+		got, err := rule.occurrences(start)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.rule, err)
+		}
+		var formatted []string
+		for _, d := range got {
+			formatted = append(formatted, d.Format("2006-01-02"))
+		}
+		if strings.Join(formatted, ",") != strings.Join(tc.want, ",") {
+			t.Fatalf("%q: want %v, got %v", tc.rule, tc.want, formatted)
+		}
+	}
+}
+
+func TestParseRepeatRejectsInvalidRules(t *testing.T) {
+	dates := newDateContext(Options{})
+	for _, rule := range []string{"weekly", "sometimes x2", "weekly on 1st workday x2", "daily on mon x2", "monthly on mon x2", "weekly x0"} {
+		if _, err := parseRepeat(rule, dates); err == nil {
+			t.Fatalf("%q: expected error", rule)
+		}
+	}
+}
+
+func TestReadExpandsRepeatRows(t *testing.T) {
+	content := `id,name,start,end,duration,depends_on,repeat
+std,Standup,2026-01-05,,,,weekly x3
+,Retro,,,2d,Standup,
+`
+	tasks, _, _, err := ReadFrom(strings.NewReader(content), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 4 {
+		t.Fatalf("expected 3 occurrences and 1 task, got %d", len(tasks))
+	}
+	second := tasks[1]
+	if second.ID != "std#2" || second.Name != "Standup #2" || second.DurationDays != 1 {
+		t.Fatalf("unexpected occurrence: %#v", second)
+	}
+	if second.Start == nil || second.Start.Format("2006-01-02") != "2026-01-12" {
+		t.Fatalf("unexpected occurrence start: %v", second.Start)
+	}
+	if occ := second.Occurrence; occ == nil || occ.SeriesID != "std" || occ.Index != 2 || occ.Count != 3 {
+		t.Fatalf("unexpected occurrence info: %#v", occ)
+	}
+	if got := tasks[3].DependsOn; len(got) != 1 || got[0] != "std#3" {
+		t.Fatalf("expected dependency on the last occurrence, got %#v", got)
+	}
+}
+
+func TestReadRejectsRepeatWithoutStart(t *testing.T) {
+	content := `name,start,end,duration,depends_on,繰り返し
+Kickoff,2026-01-05,,1d,,
+Standup,,,,Kickoff,weekly x3
+`
+	_, _, _, err := ReadFrom(strings.NewReader(content), Options{})
+	if err == nil || !strings.Contains(err.Error(), "row 3") {
+		t.Fatalf("expected row 3 error, got %v", err)
+	}
+}
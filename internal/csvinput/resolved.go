@@ -0,0 +1,182 @@
+package csvinput
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ganttgen/internal/model"
+)
+
+// Headers of the columns added by Resolve, by header language.
+var computedHeaders = map[bool][2]string{
+	false: {"computed_start", "computed_end"},
+	true:  {"計算開始", "計算終了"},
+}
+
+// Resolve returns raw, a CSV file as read with opts, with the computed start and end of
+// the scheduled tasks in computed_start and computed_end columns (計算開始 and 計算終了
+// when the name column has a Japanese header). Existing computed columns are
+// overwritten and missing ones are appended; everything else, including quoting, line
+// endings and the encoding, is kept as it was. A repeat row gets the span of its
+// occurrences; headings, display-only and anchor rows get empty cells.
+func Resolve(raw []byte, scheduled []model.Task, opts Options) ([]byte, error) {
+	parsed, err := parseBytes(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := inputEncoding(raw, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeAs(raw, kind)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]model.Task, len(scheduled))
+	for _, t := range scheduled {
+		if !t.IsHeading && !t.DisplayOnly {
+			byKey[t.Key()] = t
+		}
+	}
+
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(decoded)
+	}
+	reader := csv.NewReader(bytes.NewReader(decoded))
+	reader.Comma = delimiter
+	// lineStarts maps the line numbers of the reader to byte offsets.
+	lineStarts := []int{0}
+	for i, b := range decoded {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	fieldStart := func(field int) int {
+		line, column := reader.FieldPos(field)
+		return lineStarts[line-1] + column - 1
+	}
+
+	var (
+		out     bytes.Buffer
+		written int
+		columns [2]int
+	)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if errors.Is(err, csv.ErrFieldCount) {
+				return nil, fmt.Errorf("row %d: inconsistent field count", row)
+			}
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		var values [2]string
+		if row == 1 {
+			colIndex, _, err := mapColumns(record, headerAliases(opts.Columns))
+			if err != nil {
+				return nil, err
+			}
+			japanese := false
+			for _, r := range record[colIndex["name"]] {
+				japanese = japanese || r >= utf8.RuneSelf
+			}
+			for i, key := range []string{"computed_start", "computed_end"} {
+				columns[i] = -1
+				if idx, ok := colIndex[key]; ok {
+					columns[i] = idx
+				}
+			}
+			values = computedHeaders[japanese]
+		} else if keys := parsed.rowKeys[row]; len(keys) > 0 {
+			values = computedSpan(keys, byKey)
+		}
+
+		end := int(reader.InputOffset())
+		if end > 0 && decoded[end-1] == '\n' {
+			end--
+			if end > 0 && decoded[end-1] == '\r' {
+				end--
+			}
+		}
+		// Existing columns are replaced in place, quotes included; missing ones are appended.
+		var (
+			edits    []fieldEdit
+			appended []string
+		)
+		for i, idx := range columns {
+			if idx < 0 {
+				appended = append(appended, values[i])
+				continue
+			}
+			to := end
+			if idx+1 < len(record) {
+				to = fieldStart(idx+1) - utf8.RuneLen(delimiter)
+			}
+			edits = append(edits, fieldEdit{from: fieldStart(idx), to: to, value: values[i]})
+		}
+		slices.SortFunc(edits, func(a, b fieldEdit) int { return a.from - b.from })
+		for _, e := range edits {
+			out.Write(decoded[written:e.from])
+			out.WriteString(csvField(e.value, delimiter))
+			written = e.to
+		}
+		out.Write(decoded[written:end])
+		for _, value := range appended {
+			out.WriteRune(delimiter)
+			out.WriteString(csvField(value, delimiter))
+		}
+		written = end
+	}
+	out.Write(decoded[written:])
+	return encodeAs(out.Bytes(), kind, bomPrefix(raw))
+}
+
+// fieldEdit replaces the bytes of a field.
+type fieldEdit struct {
+	from, to int
+	value    string
+}
+
+// computedSpan returns the first computed start and the last computed end of the tasks
+// read from one row.
+func computedSpan(keys []string, byKey map[string]model.Task) [2]string {
+	var (
+		start, end time.Time
+		found      bool
+	)
+	for _, key := range keys {
+		t, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		if !found || t.ComputedStart.Before(start) {
+			start = t.ComputedStart
+		}
+		if !found || t.ComputedEnd.After(end) {
+			end = t.ComputedEnd
+		}
+		found = true
+	}
+	if !found {
+		return [2]string{}
+	}
+	return [2]string{start.Format("2006-01-02"), end.Format("2006-01-02")}
+}
+
+// csvField quotes value when the CSV format requires it.
+func csvField(value string, delimiter rune) string {
+	if !strings.ContainsRune(value, delimiter) && !strings.ContainsAny(value, "\"\r\n") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
@@ -0,0 +1,80 @@
+package csvinput
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"ganttgen/internal/scheduler"
+)
+
+// resolveCSV reads, schedules and resolves raw.
+func resolveCSV(t *testing.T, raw []byte, opts Options) string {
+	t.Helper()
+	tasks, _, _, err := ReadFrom(bytes.NewReader(raw), opts)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	scheduled, err := scheduler.Schedule(tasks)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	out, err := Resolve(raw, scheduled, opts)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	return string(out)
+}
+
+func TestResolveAppendsComputedColumnsKeepingTheFile(t *testing.T) {
+	raw := "\ufeffタスク名,開始,終了,期間,依存,繰り返し,担当\r\n" +
+		"@project,2099-06-01,,,,,\r\n" +
+		"\"#設計\",,,,,,\r\n" +
+		"\"要件 \"\"A\"\"\",@project,,3d,,,\"佐藤,鈴木\"\r\n" +
+		"\r\n" +
+		"レビュー,,,2d,\"要件 \"\"A\"\"\",,\r\n" +
+		"定例,2099-06-01,,,,weekly x3,\r\n" +
+		"メモ,,,,,,\"複数\r\n行\"\r\n" +
+		",,,,,,\r\n" +
+		"リリース,,,0d,レビュー,,"
+	want := "\ufeffタスク名,開始,終了,期間,依存,繰り返し,担当,計算開始,計算終了\r\n" +
+		"@project,2099-06-01,,,,,,,\r\n" +
+		"\"#設計\",,,,,,,,\r\n" +
+		"\"要件 \"\"A\"\"\",@project,,3d,,,\"佐藤,鈴木\",2099-06-01,2099-06-03\r\n" +
+		"\r\n" +
+		"レビュー,,,2d,\"要件 \"\"A\"\"\",,,2099-06-04,2099-06-05\r\n" +
+		"定例,2099-06-01,,,,weekly x3,,2099-06-01,2099-06-15\r\n" +
+		"メモ,,,,,,\"複数\r\n行\",,\r\n" +
+		",,,,,,,,\r\n" +
+		"リリース,,,0d,レビュー,,,2099-06-05,2099-06-05"
+	if got := resolveCSV(t, []byte(raw), Options{}); got != want {
+		t.Fatalf("unexpected resolved CSV:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestResolveOverwritesComputedColumnsInShiftJIS(t *testing.T) {
+	utf8 := "name;computed_end;start;end;duration;depends_on;computed_start\n" +
+		"設計;\"old\";2099-06-01;;2d;;x\n" +
+		"実装;;;;1d;設計;\n"
+	raw, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(utf8))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	out := resolveCSV(t, raw, Options{})
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes([]byte(out))
+	if err != nil {
+		t.Fatalf("output is not Shift_JIS: %v", err)
+	}
+	want := "name;computed_end;start;end;duration;depends_on;computed_start\n" +
+		"設計;2099-06-02;2099-06-01;;2d;;2099-06-01\n" +
+		"実装;2099-06-03;;;1d;設計;2099-06-03\n"
+	if string(decoded) != want {
+		t.Fatalf("unexpected resolved CSV:\n%s", decoded)
+	}
+	// The resolved file reads like the original.
+	tasks, customColumns, _, err := ReadFrom(bytes.NewReader([]byte(out)), Options{})
+	if err != nil || len(customColumns) != 0 || len(tasks) != 2 {
+		t.Fatalf("resolved CSV does not read back: %v %v %+v", err, customColumns, tasks)
+	}
+}
@@ -0,0 +1,262 @@
+package csvinput
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// DefaultHoursPerDay converts planned workdays into hours when none is configured.
+const DefaultHoursPerDay = 8
+
+var (
+	timesheetAliases = map[string]string{
+		"date":    "date",
+		"日付":      "date",
+		"task":    "task",
+		"task_id": "task",
+		"id":      "task",
+		"name":    "task",
+		"タスク":     "task",
+		"タスク名":    "task",
+		"タスクid":   "task",
+		"person":  "person",
+		"member":  "person",
+		"担当者":     "person",
+		"氏名":      "person",
+		"hours":   "hours",
+		"時間":      "hours",
+		"工数":      "hours",
+	}
+	hoursClock = regexp.MustCompile(`^(\d+):([0-5]\d)$`)
+)
+
+// TimesheetEntry is one line of a timesheet: hours a person spent on a task on a day.
+type TimesheetEntry struct {
+	Date   time.Time
+	Task   string
+	Person string
+	Hours  float64
+	Row    int
+}
+
+// ReadTimesheet reads a timesheet CSV with date, task (ID or name), person and hours
+// columns. Encoding and delimiter are auto-detected unless opts sets them, and dates accept
+// the same expressions as the task CSV, resolved against opts.Anchors.
+func ReadTimesheet(path string, opts Options) ([]TimesheetEntry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open timesheet: %w", err)
+	}
+	decoded, err := decodeCSVBytes(raw, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(decoded)
+	}
+	reader := csv.NewReader(bytes.NewReader(decoded))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read timesheet header: %w", err)
+	}
+	col := make(map[string]int)
+	for idx, name := range header {
+		if canonical, ok := timesheetAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+			if _, seen := col[canonical]; !seen {
+				col[canonical] = idx
+			}
+		}
+	}
+	for _, required := range []string{"date", "task", "hours"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("timesheet: missing required column: %s", required)
+		}
+	}
+
+	dates := newDateContext(opts)
+	var entries []TimesheetEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("timesheet: %w", err)
+		}
+		row, _ := reader.FieldPos(0)
+		if recordAllEmpty(record) {
+			continue
+		}
+		entry := TimesheetEntry{
+			Task:   fieldValue(record, col, "task"),
+			Person: fieldValue(record, col, "person"),
+			Row:    row,
+		}
+		if entry.Task == "" {
+			return nil, fmt.Errorf("timesheet row %d: task is required", row)
+		}
+		entry.Date, err = dates.parse(fieldValue(record, col, "date"))
+		if err != nil {
+			return nil, fmt.Errorf("timesheet row %d: invalid date: %w", row, err)
+		}
+		entry.Hours, err = parseHours(fieldValue(record, col, "hours"))
+		if err != nil {
+			return nil, fmt.Errorf("timesheet row %d: invalid hours: %w", row, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseHours accepts decimal hours ("7.5", "7.5h", "7.5時間") or a clock duration ("7:30").
+func parseHours(raw string) (float64, error) {
+	if m := hoursClock.FindStringSubmatch(raw); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		return float64(h) + float64(minutes)/60, nil
+	}
+	trimmed := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(raw), "時間"), "h"))
+	hours, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || hours < 0 || math.IsInf(hours, 0) || math.IsNaN(hours) {
+		return 0, fmt.Errorf("%q is not a number of hours", raw)
+	}
+	return hours, nil
+}
+
+// ApplyTimesheet aggregates timesheet entries onto scheduled tasks. Each task with logged
+// hours gets an Effort comparing them with its planned workdays times hoursPerDay. Tasks
+// without actual columns take their actual period from the logged days, and tasks without
+// a progress value get one estimated from the hours (100% only once completed).
+func ApplyTimesheet(tasks []model.Task, entries []TimesheetEntry, hoursPerDay float64) error {
+	if hoursPerDay <= 0 {
+		hoursPerDay = DefaultHoursPerDay
+	}
+	refs := newTimesheetRefs(tasks)
+	type logged struct {
+		hours  float64
+		days   map[time.Time]bool
+		people []string
+	}
+	byTask := make(map[int]*logged)
+	for _, e := range entries {
+		i, err := refs.lookup(e.Task)
+		if err != nil {
+			return fmt.Errorf("timesheet row %d: %w", e.Row, err)
+		}
+		l := byTask[i]
+		if l == nil {
+			l = &logged{days: make(map[time.Time]bool)}
+			byTask[i] = l
+		}
+		l.hours += e.Hours
+		if e.Hours > 0 {
+			l.days[calendar.DateOnly(e.Date)] = true
+		}
+		if e.Person != "" && !slices.Contains(l.people, e.Person) {
+			l.people = append(l.people, e.Person)
+		}
+	}
+
+	for i, l := range byTask {
+		t := &tasks[i]
+		planned := 0
+		for _, seg := range t.PlanSegments() {
+			planned += calendar.CountWorkdays(seg.Start, seg.End)
+		}
+		effort := &model.Effort{
+			PlannedHours: float64(planned) * hoursPerDay,
+			ActualHours:  l.hours,
+			People:       l.people,
+		}
+		t.Effort = effort
+		if t.ComputedActualStart == nil && len(l.days) > 0 {
+			setLoggedActuals(t, l.days)
+		}
+		if t.ProgressPercent == nil && !t.IsCancelled() && effort.PlannedHours > 0 {
+			percent := 100
+			if !t.IsCompleted() {
+				percent = min(int(effort.ActualHours/effort.PlannedHours*100), 99)
+			}
+			t.ProgressPercent = &percent
+			effort.ProgressEstimated = true
+		}
+	}
+	return nil
+}
+
+// setLoggedActuals sets the actual period from the logged days. Days separated only by
+// non-working days belong to the same work period.
+func setLoggedActuals(t *model.Task, days map[time.Time]bool) {
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	segments := []model.Interval{{Start: sorted[0], End: sorted[0]}}
+	for _, d := range sorted[1:] {
+		last := &segments[len(segments)-1]
+		if calendar.CountWorkdays(last.End.AddDate(0, 0, 1), d.AddDate(0, 0, -1)) == 0 {
+			last.End = d
+			continue
+		}
+		segments = append(segments, model.Interval{Start: d, End: d})
+	}
+	t.ComputedActualStart = ptrTime(segments[0].Start)
+	t.ComputedActualEnd = ptrTime(segments[len(segments)-1].End)
+	t.ActualSegments = nil
+	if len(segments) > 1 {
+		t.ActualSegments = segments
+	}
+}
+
+// timesheetRefs finds the task a timesheet line refers to by key, ID or name.
+type timesheetRefs struct {
+	byKey   map[string]int
+	byLocal map[string][]int
+}
+
+func newTimesheetRefs(tasks []model.Task) timesheetRefs {
+	refs := timesheetRefs{byKey: make(map[string]int), byLocal: make(map[string][]int)}
+	for i, t := range tasks {
+		if t.IsHeading || t.DisplayOnly {
+			continue
+		}
+		refs.byKey[t.Key()] = i
+		refs.byLocal[t.Name] = append(refs.byLocal[t.Name], i)
+		if t.ID != "" && t.ID != t.Name {
+			refs.byLocal[t.ID] = append(refs.byLocal[t.ID], i)
+		}
+	}
+	return refs
+}
+
+func (r timesheetRefs) lookup(ref string) (int, error) {
+	if i, ok := r.byKey[ref]; ok {
+		return i, nil
+	}
+	switch candidates := r.byLocal[ref]; len(candidates) {
+	case 0:
+		return 0, fmt.Errorf("unknown task %q", ref)
+	case 1:
+		return candidates[0], nil
+	default:
+		return 0, fmt.Errorf("ambiguous task %q (use its id)", ref)
+	}
+}
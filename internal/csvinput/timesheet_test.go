@@ -0,0 +1,90 @@
+package csvinput
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+func TestReadTimesheet(t *testing.T) {
+	content := "日付;タスク;担当者;工数\n2026/1/5;a;佐藤;8\n2026-01-06;設計;鈴木;7:30\n\n2026-01-07;a;;1.5h\n"
+	path := filepath.Join(t.TempDir(), "timesheet.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	entries, err := ReadTimesheet(path, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[1].Task != "設計" || entries[1].Person != "鈴木" || entries[1].Hours != 7.5 {
+		t.Fatalf("unexpected entry: %#v", entries[1])
+	}
+	if entries[2].Hours != 1.5 || entries[2].Row != 5 {
+		t.Fatalf("unexpected entry: %#v", entries[2])
+	}
+}
+
+func TestReadTimesheetRejectsInvalidHours(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timesheet.csv")
+	if err := os.WriteFile(path, []byte("date,task,hours\n2026-01-05,a,lots\n"), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	if _, err := ReadTimesheet(path, Options{}); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("expected row 2 error, got %v", err)
+	}
+}
+
+func TestApplyTimesheet(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.January, d, 0, 0, 0, 0, time.Local) }
+	done := 100
+	tasks := []model.Task{
+		{Name: "#Design", IsHeading: true},
+		{ID: "a", Name: "Design", ComputedStart: day(5), ComputedEnd: day(9)},
+		{ID: "b", Name: "Review", Status: "完了", ComputedStart: day(12), ComputedEnd: day(12)},
+		{ID: "c", Name: "Build", ProgressPercent: &done, ComputedStart: day(12), ComputedEnd: day(16),
+			ComputedActualStart: ptrTime(day(12)), ComputedActualEnd: ptrTime(day(13))},
+	}
+	entries := []TimesheetEntry{
+		{Date: day(5), Task: "a", Person: "Sato", Hours: 8},
+		{Date: day(6), Task: "Design", Person: "Suzuki", Hours: 6},
+		{Date: day(8), Task: "a", Person: "Sato", Hours: 6},
+		{Date: day(12), Task: "Review", Hours: 2},
+		{Date: day(14), Task: "c", Hours: 4},
+	}
+	if err := ApplyTimesheet(tasks, entries, 8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	design := tasks[1]
+	if design.Effort == nil || design.Effort.PlannedHours != 40 || design.Effort.ActualHours != 20 {
+		t.Fatalf("unexpected effort: %#v", design.Effort)
+	}
+	if got := strings.Join(design.Effort.People, ","); got != "Sato,Suzuki" {
+		t.Fatalf("unexpected people: %q", got)
+	}
+	if design.ProgressPercent == nil || *design.ProgressPercent != 50 || !design.Effort.ProgressEstimated {
+		t.Fatalf("expected estimated progress of 50%%, got %v", design.ProgressPercent)
+	}
+	if len(design.ActualSegments) != 2 || !design.ComputedActualEnd.Equal(day(8)) {
+		t.Fatalf("unexpected actual periods: %#v", design.ActualSegments)
+	}
+	if review := tasks[2]; review.ProgressPercent == nil || *review.ProgressPercent != 100 {
+		t.Fatalf("completed task should be estimated at 100%%, got %v", review.ProgressPercent)
+	}
+	build := tasks[3]
+	if *build.ProgressPercent != 100 || build.Effort.ProgressEstimated || !build.ComputedActualEnd.Equal(day(13)) {
+		t.Fatalf("explicit progress and actuals must be kept: %#v", build)
+	}
+
+	if err := ApplyTimesheet(tasks, []TimesheetEntry{{Date: day(5), Task: "Deploy", Hours: 1, Row: 7}}, 8); err == nil || !strings.Contains(err.Error(), "row 7") {
+		t.Fatalf("expected unknown task error on row 7, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StatusSemantic is the meaning of a status value independent of its wording.
+type StatusSemantic string
+
+const (
+	SemanticNotStarted StatusSemantic = "not-started"
+	SemanticInProgress StatusSemantic = "in-progress"
+	SemanticBlocked    StatusSemantic = "blocked"
+	SemanticDone       StatusSemantic = "done"
+	SemanticCancelled  StatusSemantic = "cancelled"
+)
+
+// Semantics lists every status semantic in display order.
+var Semantics = []StatusSemantic{SemanticNotStarted, SemanticInProgress, SemanticBlocked, SemanticDone, SemanticCancelled}
+
+var semanticLabels = map[StatusSemantic]string{
+	SemanticNotStarted: "未着手",
+	SemanticInProgress: "進行中",
+	SemanticBlocked:    "保留",
+	SemanticDone:       "完了",
+	SemanticCancelled:  "中止",
+}
+
+// Label returns the Japanese display label of the semantic.
+func (s StatusSemantic) Label() string {
+	return semanticLabels[s]
+}
+
+// StatusDef defines one status of the project vocabulary.
+type StatusDef struct {
+	Name     string         `yaml:"name"`
+	Aliases  []string       `yaml:"aliases,omitempty"`
+	Color    string         `yaml:"color,omitempty"`
+	Semantic StatusSemantic `yaml:"semantic"`
+}
+
+var (
+	statusMu sync.RWMutex
+	// statusDefs is the user-defined vocabulary; empty means any status is accepted.
+	statusDefs []StatusDef
+	// extraCancelled/extraCompleted are the aliases registered by SetStatusAliases.
+	extraCancelled, extraCompleted []string
+	statusLookup                   = buildStatusLookup(nil, nil, nil)
+
+	colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|rgba?\([0-9.,%\s]+\)|hsla?\([0-9.,%\s]+\))$`)
+)
+
+// SetStatusAliases registers additional status values treated as cancelled or completed,
+// on top of the built-in cancelled/中止 and completed/完了. Passing nil restores the defaults.
+func SetStatusAliases(cancelled, completed []string) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	extraCancelled, extraCompleted = cancelled, completed
+	statusLookup = buildStatusLookup(statusDefs, cancelled, completed)
+}
+
+// SetStatuses registers the project status vocabulary. Once set, statuses outside the
+// vocabulary (and the built-in/alias values) are reported by KnownStatus as unknown.
+// Passing nil clears the vocabulary.
+func SetStatuses(defs []StatusDef) error {
+	if err := ValidateStatuses(defs); err != nil {
+		return err
+	}
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statusDefs = append([]StatusDef(nil), defs...)
+	statusLookup = buildStatusLookup(statusDefs, extraCancelled, extraCompleted)
+	return nil
+}
+
+// LoadStatusesYAML reads a status vocabulary and registers it.
+func LoadStatusesYAML(path string) error {
+	defs, err := ReadStatusesYAML(path)
+	if err != nil {
+		return err
+	}
+	return SetStatuses(defs)
+}
+
+// ReadStatusesYAML reads a status vocabulary without registering it.
+// Supported formats:
+//   - A top-level list of status definitions
+//   - A map with key "statuses" pointing to a list of status definitions
+func ReadStatusesYAML(path string) ([]StatusDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read statuses yaml: %w", err)
+	}
+	var withKey struct {
+		Statuses []StatusDef `yaml:"statuses"`
+	}
+	var defs []StatusDef
+	if err := yaml.Unmarshal(data, &withKey); err == nil && len(withKey.Statuses) > 0 {
+		defs = withKey.Statuses
+	} else if err := yaml.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("decode statuses yaml: %w", err)
+	}
+	return defs, nil
+}
+
+// ValidateStatuses checks names, semantics, colors and alias uniqueness.
+func ValidateStatuses(defs []StatusDef) error {
+	seen := make(map[string]string)
+	for _, def := range defs {
+		if strings.TrimSpace(def.Name) == "" {
+			return fmt.Errorf("status without name")
+		}
+		if _, ok := semanticLabels[def.Semantic]; !ok {
+			return fmt.Errorf("status %q: unknown semantic %q (use not-started, in-progress, blocked, done or cancelled)", def.Name, def.Semantic)
+		}
+		if def.Color != "" && !IsValidColor(def.Color) {
+			return fmt.Errorf("status %q: invalid color %q", def.Name, def.Color)
+		}
+		for _, v := range append([]string{def.Name}, def.Aliases...) {
+			key := normalizeStatus(v)
+			if other, dup := seen[key]; dup {
+				return fmt.Errorf("status value %q is used by both %q and %q", v, other, def.Name)
+			}
+			seen[key] = def.Name
+		}
+	}
+	return nil
+}
+
+// IsValidColor reports whether value is a CSS color this tool accepts:
+// #hex, a color name, or an rgb()/rgba()/hsl()/hsla() function.
+func IsValidColor(value string) bool {
+	return colorPattern.MatchString(value)
+}
+
+// Statuses returns the registered vocabulary in definition order.
+func Statuses() []StatusDef {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return append([]StatusDef(nil), statusDefs...)
+}
+
+// LookupStatus resolves a status value (name or alias, case-insensitive) to its definition.
+func LookupStatus(status string) (StatusDef, bool) {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	def, ok := statusLookup[normalizeStatus(status)]
+	return def, ok
+}
+
+// KnownStatus reports whether the status is acceptable: empty, or any value when no
+// vocabulary is registered, or a value resolvable by LookupStatus otherwise.
+func KnownStatus(status string) bool {
+	if normalizeStatus(status) == "" {
+		return true
+	}
+	statusMu.RLock()
+	configured := len(statusDefs) > 0
+	statusMu.RUnlock()
+	if !configured {
+		return true
+	}
+	_, ok := LookupStatus(status)
+	return ok
+}
+
+func buildStatusLookup(defs []StatusDef, cancelled, completed []string) map[string]StatusDef {
+	lookup := make(map[string]StatusDef)
+	add := func(value string, def StatusDef) {
+		if key := normalizeStatus(value); key != "" {
+			lookup[key] = def
+		}
+	}
+	cancelledDef := StatusDef{Name: "中止", Semantic: SemanticCancelled}
+	completedDef := StatusDef{Name: "完了", Semantic: SemanticDone}
+	for _, v := range append([]string{"cancelled", "中止"}, cancelled...) {
+		add(v, cancelledDef)
+	}
+	for _, v := range append([]string{"completed", "完了"}, completed...) {
+		add(v, completedDef)
+	}
+	// User definitions win over the built-in values.
+	for _, def := range defs {
+		add(def.Name, def)
+		for _, alias := range def.Aliases {
+			add(alias, def)
+		}
+	}
+	return lookup
+}
+
+func normalizeStatus(status string) string {
+	return strings.TrimSpace(strings.ToLower(status))
+}
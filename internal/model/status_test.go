@@ -0,0 +1,84 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetStatusesDrivesSemantics(t *testing.T) {
+	err := SetStatuses([]StatusDef{
+		{Name: "未着手", Semantic: SemanticNotStarted},
+		{Name: "進行中", Aliases: []string{"WIP"}, Color: "#2563eb", Semantic: SemanticInProgress},
+		{Name: "保留", Semantic: SemanticBlocked},
+		{Name: "レビュー待ち", Semantic: SemanticInProgress},
+		{Name: "済", Semantic: SemanticDone},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { SetStatuses(nil) })
+
+	if got := (Task{Status: "wip"}).Semantic(); got != SemanticInProgress {
+		t.Fatalf("expected alias to resolve to in-progress, got %q", got)
+	}
+	if def, ok := LookupStatus(" WIP "); !ok || def.Name != "進行中" || def.Color != "#2563eb" {
+		t.Fatalf("unexpected lookup result: %+v %v", def, ok)
+	}
+	if !(Task{Status: "済"}).IsCompleted() || !(Task{Status: "完了"}).IsCompleted() {
+		t.Fatalf("expected configured and built-in done statuses")
+	}
+	if !KnownStatus("保留") || !KnownStatus("") || !KnownStatus("cancelled") {
+		t.Fatalf("expected defined, empty and built-in statuses to be known")
+	}
+	if KnownStatus("保留中") {
+		t.Fatalf("expected undefined status to be unknown")
+	}
+}
+
+func TestKnownStatusWithoutVocabulary(t *testing.T) {
+	if !KnownStatus("anything") {
+		t.Fatalf("expected any status to be accepted without a vocabulary")
+	}
+}
+
+func TestValidateStatuses(t *testing.T) {
+	cases := []struct {
+		name string
+		defs []StatusDef
+	}{
+		{name: "missing name", defs: []StatusDef{{Semantic: SemanticDone}}},
+		{name: "bad semantic", defs: []StatusDef{{Name: "x", Semantic: "finished"}}},
+		{name: "bad color", defs: []StatusDef{{Name: "x", Color: "#12;x", Semantic: SemanticDone}}},
+		{name: "duplicate alias", defs: []StatusDef{
+			{Name: "a", Aliases: []string{"same"}, Semantic: SemanticDone},
+			{Name: "b", Aliases: []string{"Same"}, Semantic: SemanticBlocked},
+		}},
+	}
+	for _, tc := range cases {
+		if err := ValidateStatuses(tc.defs); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestLoadStatusesYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statuses.yaml")
+	content := `statuses:
+  - name: 保留
+    aliases: [blocked, "on hold"]
+    color: "rgb(234, 179, 8)"
+    semantic: blocked
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := LoadStatusesYAML(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { SetStatuses(nil) })
+
+	if got := (Task{Status: "On Hold"}).Semantic(); got != SemanticBlocked {
+		t.Fatalf("expected blocked semantic, got %q", got)
+	}
+}
@@ -1,22 +1,26 @@
 package model
 
 import (
-	"strings"
 	"time"
 )
 
 // Task represents a single CSV-defined task and its computed schedule.
 type Task struct {
-	Name                string
-	IsHeading           bool
-	DisplayOnly         bool
-	Notes               string
-	Status              string
-	ProgressPercent     *int
-	CustomValues        []string
-	Start               *time.Time
-	End                 *time.Time
-	DurationDays        int
+	ID              string
+	Name            string
+	Namespace       string
+	IsHeading       bool
+	Level           int
+	DisplayOnly     bool
+	Notes           string
+	Status          string
+	ProgressPercent *int
+	CustomValues    []string
+	Start           *time.Time
+	End             *time.Time
+	DurationDays    int
+	// Milestone marks a zero-length task (duration 0d) that is scheduled on a single day.
+	Milestone           bool
 	ActualStart         *time.Time
 	ActualEnd           *time.Time
 	ActualDurationDays  int
@@ -25,6 +29,75 @@ type Task struct {
 	ComputedEnd         time.Time
 	ComputedActualStart *time.Time
 	ComputedActualEnd   *time.Time
+	// Pauses are planned interruptions; the task does not progress on these days.
+	Pauses []Interval
+	// ComputedSegments are the worked parts of the plan when pauses split it, nil otherwise.
+	ComputedSegments []Interval
+	// ActualSegments are the actual work periods when more than one was recorded, nil otherwise.
+	ActualSegments []Interval
+	// Occurrence is set on tasks generated from a repeat row.
+	Occurrence *Occurrence
+	// Effort is set when hours were logged for the task in a timesheet.
+	Effort *Effort
+}
+
+// Effort compares the planned work on a task with the hours logged against it.
+type Effort struct {
+	PlannedHours float64
+	ActualHours  float64
+	// People lists who logged time, in order of first entry.
+	People []string
+	// ProgressEstimated is set when ProgressPercent was derived from the hours.
+	ProgressEstimated bool
+}
+
+// Interval is an inclusive range of days.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether the day of t lies within the interval.
+func (iv Interval) Contains(t time.Time) bool {
+	return !t.Before(iv.Start) && !t.After(iv.End)
+}
+
+// Occurrence links a generated task to the repeat row (series) it was expanded from.
+type Occurrence struct {
+	SeriesID   string
+	SeriesName string
+	// Index is the 1-based position of the task in its series.
+	Index int
+	Count int
+}
+
+// SeriesKey returns the key shared by all occurrences of the same repeat row,
+// or "" for tasks that do not belong to a series.
+func (t Task) SeriesKey() string {
+	if t.Occurrence == nil {
+		return ""
+	}
+	key := t.Occurrence.SeriesName
+	if t.Occurrence.SeriesID != "" {
+		key = t.Occurrence.SeriesID
+	}
+	if t.Namespace != "" {
+		return t.Namespace + ":" + key
+	}
+	return key
+}
+
+// Key returns the identifier used to reference the task: the ID when set, otherwise the name.
+// Tasks merged from several input files are qualified with their namespace ("file.csv:key").
+func (t Task) Key() string {
+	key := t.Name
+	if t.ID != "" {
+		key = t.ID
+	}
+	if t.Namespace != "" {
+		return t.Namespace + ":" + key
+	}
+	return key
 }
 
 // HasStart returns true when an absolute start date was provided.
@@ -42,6 +115,26 @@ func (t Task) HasDuration() bool {
 	return t.DurationDays > 0
 }
 
+// PlanSegments returns the planned intervals: the computed segments when the task is
+// paused, otherwise the single interval from ComputedStart to ComputedEnd.
+func (t Task) PlanSegments() []Interval {
+	if len(t.ComputedSegments) > 0 {
+		return t.ComputedSegments
+	}
+	return []Interval{{Start: t.ComputedStart, End: t.ComputedEnd}}
+}
+
+// ActualIntervals returns the actual work periods, or nil when there are no actuals.
+func (t Task) ActualIntervals() []Interval {
+	if len(t.ActualSegments) > 0 {
+		return t.ActualSegments
+	}
+	if !t.HasActual() {
+		return nil
+	}
+	return []Interval{{Start: *t.ComputedActualStart, End: *t.ComputedActualEnd}}
+}
+
 // HasActual returns true when any actual-related date exists.
 func (t Task) HasActual() bool {
 	return t.ComputedActualStart != nil && t.ComputedActualEnd != nil
@@ -49,12 +142,19 @@ func (t Task) HasActual() bool {
 
 // IsCancelled reports whether the task is marked as cancelled by status.
 func (t Task) IsCancelled() bool {
-	status := strings.TrimSpace(strings.ToLower(t.Status))
-	return status == "cancelled" || status == "中止"
+	return t.Semantic() == SemanticCancelled
 }
 
 // IsCompleted reports whether the task is marked as completed by status.
 func (t Task) IsCompleted() bool {
-	status := strings.TrimSpace(strings.ToLower(t.Status))
-	return status == "completed" || status == "完了"
+	return t.Semantic() == SemanticDone
+}
+
+// Semantic returns the meaning of the task status, or an empty semantic when unknown.
+func (t Task) Semantic() StatusSemantic {
+	def, ok := LookupStatus(t.Status)
+	if !ok {
+		return ""
+	}
+	return def.Semantic
 }
@@ -18,3 +18,41 @@ func TestIsCompleted(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyPrefersID(t *testing.T) {
+	if got := (Task{ID: "T-1", Name: "Design"}).Key(); got != "T-1" {
+		t.Fatalf("expected ID as key, got %q", got)
+	}
+	if got := (Task{Name: "Design"}).Key(); got != "Design" {
+		t.Fatalf("expected name as key, got %q", got)
+	}
+	if got := (Task{Name: "Design", Namespace: "backend.csv"}).Key(); got != "backend.csv:Design" {
+		t.Fatalf("expected namespaced key, got %q", got)
+	}
+}
+
+func TestSetStatusAliases(t *testing.T) {
+	SetStatusAliases([]string{"Dropped"}, []string{"済"})
+	t.Cleanup(func() { SetStatusAliases(nil, nil) })
+
+	if !(Task{Status: "dropped"}).IsCancelled() || !(Task{Status: "中止"}).IsCancelled() {
+		t.Fatalf("expected configured and default cancelled statuses")
+	}
+	if !(Task{Status: "済"}).IsCompleted() || !(Task{Status: "completed"}).IsCompleted() {
+		t.Fatalf("expected configured and default completed statuses")
+	}
+}
+
+func TestSeriesKey(t *testing.T) {
+	occ := &Occurrence{SeriesID: "std", SeriesName: "Standup", Index: 1, Count: 3}
+	if got := (Task{Name: "Standup #1", Occurrence: occ}).SeriesKey(); got != "std" {
+		t.Fatalf("expected series id as key, got %q", got)
+	}
+	named := &Occurrence{SeriesName: "Standup", Index: 1, Count: 3}
+	if got := (Task{Name: "Standup #1", Namespace: "team.csv", Occurrence: named}).SeriesKey(); got != "team.csv:Standup" {
+		t.Fatalf("expected namespaced series name, got %q", got)
+	}
+	if got := (Task{Name: "Design"}).SeriesKey(); got != "" {
+		t.Fatalf("expected empty key outside a series, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package mspdi
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// namespace is the XML namespace of Microsoft Project files.
+const namespace = "http://schemas.microsoft.com/project"
+
+const (
+	dateTimeLayout = "2006-01-02T15:04:05"
+	defaultTitle   = "Gantt Chart"
+	// saveVersion is the file format of Project 2010.
+	saveVersion = 14
+	// The exported calendar works 8:00-12:00 and 13:00-17:00, eight hours a day.
+	dayStartTime  = "08:00:00"
+	dayFinishTime = "17:00:00"
+	hoursPerDay   = 8
+	calendarUID   = 1
+)
+
+// Codes of the MSPDI enumerations that ganttgen uses.
+const (
+	exceptionDaily               = 1
+	durationFormatDays           = 7
+	linkFinishToStart            = 1
+	constraintAsSoonAsPossible   = 0
+	constraintStartNoEarlierThan = 4
+)
+
+// xmlProject is the part of an MSPDI document that ganttgen reads and writes. Element
+// order follows the MSPDI schema.
+type xmlProject struct {
+	XMLName           xml.Name      `xml:"Project"`
+	Xmlns             string        `xml:"xmlns,attr,omitempty"`
+	SaveVersion       int           `xml:"SaveVersion,omitempty"`
+	Name              string        `xml:"Name,omitempty"`
+	Title             string        `xml:"Title,omitempty"`
+	ScheduleFromStart *xmlBool      `xml:"ScheduleFromStart,omitempty"`
+	StartDate         string        `xml:"StartDate,omitempty"`
+	FinishDate        string        `xml:"FinishDate,omitempty"`
+	CalendarUID       int           `xml:"CalendarUID,omitempty"`
+	DefaultStartTime  string        `xml:"DefaultStartTime,omitempty"`
+	DefaultFinishTime string        `xml:"DefaultFinishTime,omitempty"`
+	MinutesPerDay     int           `xml:"MinutesPerDay,omitempty"`
+	MinutesPerWeek    int           `xml:"MinutesPerWeek,omitempty"`
+	Calendars         []xmlCalendar `xml:"Calendars>Calendar"`
+	Tasks             []xmlTask     `xml:"Tasks>Task"`
+}
+
+type xmlCalendar struct {
+	UID             int            `xml:"UID"`
+	Name            string         `xml:"Name,omitempty"`
+	IsBaseCalendar  xmlBool        `xml:"IsBaseCalendar"`
+	BaseCalendarUID int            `xml:"BaseCalendarUID"`
+	WeekDays        []xmlWeekDay   `xml:"WeekDays>WeekDay"`
+	Exceptions      []xmlException `xml:"Exceptions>Exception"`
+}
+
+// xmlWeekDay is a day of the week (DayType 1 = Sunday ... 7 = Saturday) or, in files from
+// older versions, an exception period (DayType 0).
+type xmlWeekDay struct {
+	DayType      int              `xml:"DayType"`
+	DayWorking   xmlBool          `xml:"DayWorking"`
+	TimePeriod   *xmlTimePeriod   `xml:"TimePeriod,omitempty"`
+	WorkingTimes []xmlWorkingTime `xml:"WorkingTimes>WorkingTime,omitempty"`
+}
+
+type xmlException struct {
+	EnteredByOccurrences xmlBool          `xml:"EnteredByOccurrences"`
+	TimePeriod           xmlTimePeriod    `xml:"TimePeriod"`
+	Occurrences          int              `xml:"Occurrences"`
+	Name                 string           `xml:"Name,omitempty"`
+	Type                 int              `xml:"Type"`
+	DayWorking           xmlBool          `xml:"DayWorking"`
+	WorkingTimes         []xmlWorkingTime `xml:"WorkingTimes>WorkingTime,omitempty"`
+}
+
+type xmlTimePeriod struct {
+	FromDate string `xml:"FromDate"`
+	ToDate   string `xml:"ToDate"`
+}
+
+type xmlWorkingTime struct {
+	FromTime string `xml:"FromTime"`
+	ToTime   string `xml:"ToTime"`
+}
+
+type xmlTask struct {
+	UID  int    `xml:"UID"`
+	ID   int    `xml:"ID"`
+	Name string `xml:"Name,omitempty"`
+	// Active is false for inactive (cancelled) tasks; a missing element means active.
+	Active          *xmlBool  `xml:"Active,omitempty"`
+	IsNull          xmlBool   `xml:"IsNull"`
+	WBS             string    `xml:"WBS,omitempty"`
+	OutlineNumber   string    `xml:"OutlineNumber,omitempty"`
+	OutlineLevel    int       `xml:"OutlineLevel"`
+	Start           string    `xml:"Start,omitempty"`
+	Finish          string    `xml:"Finish,omitempty"`
+	Duration        string    `xml:"Duration,omitempty"`
+	DurationFormat  int       `xml:"DurationFormat,omitempty"`
+	Milestone       xmlBool   `xml:"Milestone"`
+	Summary         xmlBool   `xml:"Summary"`
+	PercentComplete int       `xml:"PercentComplete"`
+	ActualStart     string    `xml:"ActualStart,omitempty"`
+	ActualFinish    string    `xml:"ActualFinish,omitempty"`
+	ConstraintType  int       `xml:"ConstraintType"`
+	ConstraintDate  string    `xml:"ConstraintDate,omitempty"`
+	Notes           string    `xml:"Notes,omitempty"`
+	PredecessorLink []xmlLink `xml:"PredecessorLink"`
+}
+
+type xmlLink struct {
+	PredecessorUID int `xml:"PredecessorUID"`
+	// Type is the link type; a missing element means finish-to-start.
+	Type      *int `xml:"Type,omitempty"`
+	LinkLag   int  `xml:"LinkLag"`
+	LagFormat int  `xml:"LagFormat,omitempty"`
+}
+
+// xmlBool is an MSPDI boolean, written as 0 or 1.
+type xmlBool bool
+
+func (b xmlBool) MarshalText() ([]byte, error) {
+	if b {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+}
+
+func (b *xmlBool) UnmarshalText(text []byte) error {
+	v := strings.TrimSpace(string(text))
+	*b = xmlBool(v == "1" || strings.EqualFold(v, "true"))
+	return nil
+}
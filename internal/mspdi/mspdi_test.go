@@ -0,0 +1,217 @@
+package mspdi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+	"ganttgen/internal/scheduler"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func ptrInt(v int) *int {
+	return &v
+}
+
+func sampleTasks(t *testing.T) []model.Task {
+	t.Helper()
+	tasks := []model.Task{
+		{Name: "設計", IsHeading: true},
+		{ID: "a", Name: "要件", Start: ptrTime(day(2024, time.June, 3)), DurationDays: 2, ProgressPercent: ptrInt(100),
+			ActualStart: ptrTime(day(2024, time.June, 3)), ActualEnd: ptrTime(day(2024, time.June, 4)),
+			ComputedActualStart: ptrTime(day(2024, time.June, 3)), ComputedActualEnd: ptrTime(day(2024, time.June, 4))},
+		{ID: "b", Name: "設計書", DependsOn: []string{"a"}, DurationDays: 5, ProgressPercent: ptrInt(40), Notes: "レビュー込み"},
+		{ID: "c", Name: "画面", Start: ptrTime(day(2024, time.June, 17)), DependsOn: []string{"a"}, DurationDays: 2},
+		{Name: "実装", IsHeading: true},
+		{ID: "d", Name: "実装", DependsOn: []string{"b", "c"}, DurationDays: 3},
+		{ID: "x", Name: "旧案", Status: "中止", Start: ptrTime(day(2024, time.June, 3)), DurationDays: 1},
+		{ID: "r", Name: "リリース", DependsOn: []string{"d"}, Milestone: true},
+		{Name: "定例", DisplayOnly: true},
+	}
+	scheduled, err := scheduler.Schedule(tasks)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	return scheduled
+}
+
+func TestWriteExportsOutlineLinksAndCalendar(t *testing.T) {
+	calendar.SetHolidays([]time.Time{day(2024, time.June, 12)})
+	t.Cleanup(func() { calendar.SetHolidays(nil) })
+	data, err := Write(sampleTasks(t), Options{Title: "計画"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		`<Project xmlns="http://schemas.microsoft.com/project">`,
+		"<Title>計画</Title>",
+		"<StartDate>2024-06-03T08:00:00</StartDate>",
+		// 設計 is a summary task over 要件, 設計書 and 画面.
+		"<Name>設計</Name>\n      <IsNull>0</IsNull>\n      <WBS>1</WBS>\n      <OutlineNumber>1</OutlineNumber>\n      <OutlineLevel>1</OutlineLevel>\n      <Start>2024-06-03T08:00:00</Start>\n      <Finish>2024-06-18T17:00:00</Finish>",
+		"<Name>設計書</Name>\n      <IsNull>0</IsNull>\n      <WBS>1.2</WBS>\n      <OutlineNumber>1.2</OutlineNumber>\n      <OutlineLevel>2</OutlineLevel>\n      <Start>2024-06-05T08:00:00</Start>\n      <Finish>2024-06-11T17:00:00</Finish>\n      <Duration>PT40H0M0S</Duration>",
+		"<PercentComplete>40</PercentComplete>",
+		"<Notes>レビュー込み</Notes>\n      <PredecessorLink>\n        <PredecessorUID>2</PredecessorUID>\n        <Type>1</Type>",
+		"<ActualStart>2024-06-03T08:00:00</ActualStart>\n      <ActualFinish>2024-06-04T17:00:00</ActualFinish>",
+		// 画面 starts later than its dependency allows, so it keeps its date.
+		"<ConstraintType>4</ConstraintType>\n      <ConstraintDate>2024-06-17T08:00:00</ConstraintDate>",
+		// Active follows Name, as the schema orders it.
+		"<Name>旧案</Name>\n      <Active>0</Active>\n      <IsNull>0</IsNull>",
+		"<Start>2024-06-21T17:00:00</Start>\n      <Finish>2024-06-21T17:00:00</Finish>\n      <Duration>PT0H0M0S</Duration>\n      <DurationFormat>7</DurationFormat>\n      <Milestone>1</Milestone>",
+		"<DayType>1</DayType>\n          <DayWorking>0</DayWorking>",
+		"<FromDate>2024-06-12T00:00:00</FromDate>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "定例") {
+		t.Fatalf("display-only row should be left out")
+	}
+}
+
+func TestReadRoundTripsWrittenProject(t *testing.T) {
+	calendar.SetHolidays([]time.Time{day(2024, time.June, 12)})
+	t.Cleanup(func() { calendar.SetHolidays(nil) })
+	original := sampleTasks(t)
+	data, err := Write(original, Options{Title: "計画"})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	p, err := Read(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if p.Title != "計画" || !p.HasProgress {
+		t.Fatalf("unexpected project: %q progress=%v", p.Title, p.HasProgress)
+	}
+	if len(p.Holidays) != 1 || !p.Holidays[0].Equal(day(2024, time.June, 12)) || len(p.Weekends) != 2 {
+		t.Fatalf("unexpected calendar: %v %v", p.Weekends, p.Holidays)
+	}
+	if got := p.Tasks[2]; got.Start != nil || len(got.DependsOn) != 1 || got.DependsOn[0] != "2" {
+		t.Fatalf("dependent task should follow its link: %#v", got)
+	}
+	scheduled, err := scheduler.Schedule(p.Tasks)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	var want []model.Task
+	for _, task := range original {
+		if !task.DisplayOnly {
+			want = append(want, task)
+		}
+	}
+	if len(scheduled) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(scheduled))
+	}
+	for i, got := range scheduled {
+		w := want[i]
+		if got.Name != w.Name || got.IsHeading != w.IsHeading || got.Milestone != w.Milestone || got.IsCancelled() != w.IsCancelled() {
+			t.Fatalf("row %d: got %#v, want %#v", i, got, w)
+		}
+		if !got.IsHeading && (!got.ComputedStart.Equal(w.ComputedStart) || !got.ComputedEnd.Equal(w.ComputedEnd)) {
+			t.Fatalf("%s: got %v - %v, want %v - %v", got.Name, got.ComputedStart, got.ComputedEnd, w.ComputedStart, w.ComputedEnd)
+		}
+	}
+	if got := scheduled[1]; got.ComputedActualEnd == nil || !got.ComputedActualEnd.Equal(day(2024, time.June, 4)) || got.Status != "完了" {
+		t.Fatalf("actuals or status lost: %#v", got)
+	}
+}
+
+func TestReadProjectFile(t *testing.T) {
+	const file = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Project xmlns="http://schemas.microsoft.com/project">
+  <Name>plan.xml</Name>
+  <CalendarUID>3</CalendarUID>
+  <MinutesPerDay>480</MinutesPerDay>
+  <Calendars>
+    <Calendar>
+      <UID>1</UID><Name>Standard</Name><IsBaseCalendar>1</IsBaseCalendar><BaseCalendarUID>-1</BaseCalendarUID>
+      <WeekDays>
+        <WeekDay><DayType>1</DayType><DayWorking>0</DayWorking></WeekDay>
+        <WeekDay><DayType>7</DayType><DayWorking>0</DayWorking></WeekDay>
+        <WeekDay><DayType>0</DayType><DayWorking>0</DayWorking><TimePeriod><FromDate>2024-06-12T00:00:00</FromDate><ToDate>2024-06-13T23:59:00</ToDate></TimePeriod></WeekDay>
+      </WeekDays>
+    </Calendar>
+    <Calendar>
+      <UID>3</UID><Name>Team</Name><IsBaseCalendar>0</IsBaseCalendar><BaseCalendarUID>1</BaseCalendarUID>
+      <Exceptions>
+        <Exception><TimePeriod><FromDate>2024-06-15T00:00:00</FromDate><ToDate>2024-06-15T23:59:00</ToDate></TimePeriod><Type>1</Type><DayWorking>1</DayWorking></Exception>
+        <Exception><TimePeriod><FromDate>2024-01-01T00:00:00</FromDate><ToDate>2030-01-01T23:59:00</ToDate></TimePeriod><Type>2</Type><DayWorking>0</DayWorking></Exception>
+      </Exceptions>
+    </Calendar>
+  </Calendars>
+  <Tasks>
+    <Task><UID>0</UID><ID>0</ID><Name>plan</Name><OutlineLevel>0</OutlineLevel><Summary>1</Summary></Task>
+    <Task><UID>1</UID><ID>1</ID><Name>Phase 1</Name><OutlineLevel>1</OutlineLevel><Summary>1</Summary>
+      <Start>2024-06-03T08:00:00</Start><Finish>2024-06-20T17:00:00</Finish></Task>
+    <Task><UID>2</UID><ID>2</ID><Name>Spec</Name><OutlineLevel>2</OutlineLevel>
+      <Start>2024-06-03T08:00:00</Start><Finish>2024-06-04T12:00:00</Finish><Duration>PT12H0M0S</Duration>
+      <PercentComplete>50</PercentComplete><ActualStart>2024-06-03T08:00:00</ActualStart></Task>
+    <Task><UID>5</UID><ID>3</ID><Name>Build</Name><OutlineLevel>2</OutlineLevel>
+      <Start>2024-06-05T08:00:00</Start><Finish>2024-06-14T17:00:00</Finish><Duration>PT48H0M0S</Duration>
+      <PredecessorLink><PredecessorUID>2</PredecessorUID><Type>1</Type><LinkLag>0</LinkLag></PredecessorLink></Task>
+    <Task><UID>6</UID><ID>4</ID><Name>Docs</Name><OutlineLevel>2</OutlineLevel>
+      <Start>2024-06-05T08:00:00</Start><Finish>2024-06-05T17:00:00</Finish><Duration>PT8H0M0S</Duration>
+      <PredecessorLink><PredecessorUID>5</PredecessorUID><Type>3</Type></PredecessorLink></Task>
+    <Task><UID>7</UID><ID>5</ID><Name>Done</Name><OutlineLevel>2</OutlineLevel>
+      <Start>2024-06-14T17:00:00</Start><Finish>2024-06-14T17:00:00</Finish><Duration>PT0H0M0S</Duration>
+      <PredecessorLink><PredecessorUID>5</PredecessorUID></PredecessorLink></Task>
+    <Task><UID>8</UID><ID>6</ID><IsNull>1</IsNull></Task>
+  </Tasks>
+</Project>
+`
+	p, err := Read(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Title != "plan" || !p.HasProgress {
+		t.Fatalf("unexpected project: %#v", p)
+	}
+	if len(p.Holidays) != 2 || len(p.Workdays) != 1 || !p.Workdays[0].Equal(day(2024, time.June, 15)) {
+		t.Fatalf("unexpected calendar: holidays %v workdays %v", p.Holidays, p.Workdays)
+	}
+	if len(p.Tasks) != 5 || !p.Tasks[0].IsHeading || p.Tasks[0].Level != 0 {
+		t.Fatalf("unexpected rows: %#v", p.Tasks)
+	}
+	spec, build, docs, done := p.Tasks[1], p.Tasks[2], p.Tasks[3], p.Tasks[4]
+	if spec.ID != "2" || spec.DurationDays != 2 || spec.Start == nil || *spec.ProgressPercent != 50 || spec.ComputedActualStart == nil {
+		t.Fatalf("unexpected Spec: %#v", spec)
+	}
+	// Build starts the day after Spec, so it only keeps the link.
+	if build.Start != nil || build.DurationDays != 6 || len(build.DependsOn) != 1 || build.DependsOn[0] != "2" {
+		t.Fatalf("unexpected Build: %#v", build)
+	}
+	// A start-to-start link cannot be kept, so Docs keeps its date instead.
+	if docs.Start == nil || len(docs.DependsOn) != 0 {
+		t.Fatalf("unexpected Docs: %#v", docs)
+	}
+	if !done.Milestone || done.Start != nil || done.DependsOn[0] != "5" {
+		t.Fatalf("unexpected milestone: %#v", done)
+	}
+
+	calendar.SetHolidays(p.Holidays)
+	calendar.SetExtraWorkdays(p.Workdays)
+	t.Cleanup(func() { calendar.SetHolidays(nil) })
+	t.Cleanup(func() { calendar.SetExtraWorkdays(nil) })
+	scheduled, err := scheduler.Schedule(p.Tasks)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	// 6/12-13 are holidays and Saturday 6/15 is worked.
+	if got := scheduled[2]; !got.ComputedStart.Equal(day(2024, time.June, 5)) || !got.ComputedEnd.Equal(day(2024, time.June, 14)) {
+		t.Fatalf("unexpected Build schedule: %v - %v", got.ComputedStart, got.ComputedEnd)
+	}
+	if got := scheduled[4]; !got.ComputedStart.Equal(day(2024, time.June, 14)) {
+		t.Fatalf("unexpected milestone date: %v", got.ComputedStart)
+	}
+}
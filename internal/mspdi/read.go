@@ -0,0 +1,344 @@
+package mspdi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// maxExceptionDays limits the days taken from a single calendar exception, so that a
+// mistyped or open-ended period cannot blow up the holiday list.
+const maxExceptionDays = 5 * 366
+
+var (
+	dateLayouts     = []string{dateTimeLayout, "2006-01-02T15:04", "2006-01-02"}
+	durationPattern = regexp.MustCompile(`^P(?:([0-9.]+)D)?(?:T(?:([0-9.]+)H)?(?:([0-9.]+)M)?(?:([0-9.]+)S)?)?$`)
+)
+
+// Project is a schedule read from an MSPDI file.
+type Project struct {
+	Title string
+	// Tasks are unscheduled rows in file order; summary tasks become headings.
+	Tasks []model.Task
+	// HasProgress is set when any task has a percent complete.
+	HasProgress bool
+	// Weekends is nil when the file has no calendar. Holidays and Workdays are the
+	// non-working and working exceptions to the weekly pattern.
+	Weekends []time.Weekday
+	Holidays []time.Time
+	Workdays []time.Time
+}
+
+// ReadFile reads an MSPDI file; see Read.
+func ReadFile(path string) (Project, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Project{}, fmt.Errorf("open MS Project XML: %w", err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read converts an MSPDI (Microsoft Project XML) document into task rows that the
+// scheduler can use. Task IDs are the Project UIDs. Finish-to-start links without lag
+// become dependencies; a task keeps its start date when it has no dependencies or starts
+// later than they allow, so the schedule matches the file. Durations are converted to
+// workdays. Recurring calendar exceptions are not read.
+func Read(r io.Reader) (Project, error) {
+	var doc xmlProject
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Project{}, fmt.Errorf("decode MS Project XML: %w", err)
+	}
+	p := Project{Title: doc.Title}
+	if p.Title == "" {
+		p.Title = strings.TrimSuffix(doc.Name, ".xml")
+	}
+	cal, ok := doc.calendar()
+	if ok {
+		p.Weekends, p.Holidays, p.Workdays = cal.days()
+	}
+	hours := float64(hoursPerDay)
+	if doc.MinutesPerDay > 0 {
+		hours = float64(doc.MinutesPerDay) / 60
+	}
+
+	byUID := make(map[int]xmlTask, len(doc.Tasks))
+	for _, xt := range doc.Tasks {
+		byUID[xt.UID] = xt
+	}
+	for _, xt := range doc.Tasks {
+		// Outline level 0 is the project summary task.
+		if xt.IsNull || xt.OutlineLevel == 0 {
+			continue
+		}
+		if xt.Summary {
+			p.Tasks = append(p.Tasks, model.Task{Name: xt.Name, IsHeading: true, Level: xt.OutlineLevel - 1, Notes: xt.Notes})
+			continue
+		}
+		task, err := readTask(xt, byUID, cal, hours)
+		if err != nil {
+			return Project{}, err
+		}
+		p.HasProgress = p.HasProgress || xt.PercentComplete > 0
+		p.Tasks = append(p.Tasks, task)
+	}
+	if len(p.Tasks) == 0 {
+		return Project{}, fmt.Errorf("no tasks in MS Project XML")
+	}
+	if !p.HasProgress {
+		for i := range p.Tasks {
+			p.Tasks[i].ProgressPercent = nil
+		}
+	}
+	return p, nil
+}
+
+func readTask(xt xmlTask, byUID map[int]xmlTask, cal workCalendar, hours float64) (model.Task, error) {
+	start, err := parseDate(xt.Start)
+	if err != nil {
+		return model.Task{}, fmt.Errorf("task %q: invalid Start: %w", xt.Name, err)
+	}
+	finish, err := parseDate(xt.Finish)
+	if err != nil {
+		return model.Task{}, fmt.Errorf("task %q: invalid Finish: %w", xt.Name, err)
+	}
+	percent := xt.PercentComplete
+	task := model.Task{ID: strconv.Itoa(xt.UID), Name: xt.Name, Notes: xt.Notes, ProgressPercent: &percent}
+	if task.Name == "" {
+		task.Name = "Task " + task.ID
+	}
+
+	days := durationDays(xt.Duration, hours)
+	task.Milestone = bool(xt.Milestone) || (days == 0 && !finish.After(start))
+	if !task.Milestone && days == 0 {
+		days = max(1, cal.count(start, finish))
+	}
+	if !task.Milestone {
+		task.DurationDays = days
+	}
+
+	followsLinks := true
+	var latest time.Time
+	for _, link := range xt.PredecessorLink {
+		pred, ok := byUID[link.PredecessorUID]
+		finishToStart := link.Type == nil || *link.Type == linkFinishToStart
+		if !ok || bool(pred.Summary) || !finishToStart || link.LinkLag != 0 {
+			// The scheduler only knows finish-to-start links between tasks.
+			followsLinks = false
+			continue
+		}
+		predFinish, err := parseDate(pred.Finish)
+		if err != nil {
+			return model.Task{}, fmt.Errorf("task %q: invalid Finish: %w", pred.Name, err)
+		}
+		if predFinish.After(latest) {
+			latest = predFinish
+		}
+		task.DependsOn = append(task.DependsOn, strconv.Itoa(pred.UID))
+	}
+	expected := cal.nextWorkdayAfter(latest)
+	if task.Milestone {
+		expected = latest
+	}
+	if !followsLinks || len(task.DependsOn) == 0 || !expected.Equal(start) {
+		task.Start = &start
+	}
+
+	switch {
+	case xt.Active != nil && !bool(*xt.Active):
+		task.Status = model.SemanticCancelled.Label()
+	case xt.PercentComplete >= 100:
+		task.Status = model.SemanticDone.Label()
+	}
+	if xt.ActualStart != "" {
+		actualStart, err := parseDate(xt.ActualStart)
+		if err != nil {
+			return model.Task{}, fmt.Errorf("task %q: invalid ActualStart: %w", xt.Name, err)
+		}
+		actualEnd := actualStart
+		if xt.ActualFinish != "" {
+			if actualEnd, err = parseDate(xt.ActualFinish); err != nil {
+				return model.Task{}, fmt.Errorf("task %q: invalid ActualFinish: %w", xt.Name, err)
+			}
+			task.ActualEnd = &actualEnd
+		}
+		if actualEnd.Before(actualStart) {
+			actualEnd = actualStart
+		}
+		task.ActualStart = &actualStart
+		task.ComputedActualStart = &actualStart
+		task.ComputedActualEnd = &actualEnd
+	}
+	return task, nil
+}
+
+// parseDate parses an MSPDI date and drops the time of day.
+func parseDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+			return calendar.DateOnly(parsed), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// durationDays converts an MSPDI duration such as PT24H0M0S into whole workdays,
+// rounding partial days up. Unreadable durations count as zero.
+func durationDays(raw string, hours float64) int {
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return 0
+	}
+	value := func(s string) float64 {
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+	total := value(m[1])*hours + value(m[2]) + value(m[3])/60 + value(m[4])/3600
+	return int(math.Ceil(total/hours - 1e-9))
+}
+
+// workCalendar is the project calendar of a file, used to tell which tasks start right
+// after their dependencies.
+type workCalendar struct {
+	weekends map[time.Weekday]bool
+	holidays map[time.Time]bool
+	workdays map[time.Time]bool
+}
+
+// calendar returns the project calendar, merged with the calendars it is based on.
+func (doc xmlProject) calendar() (workCalendar, bool) {
+	byUID := make(map[int]xmlCalendar, len(doc.Calendars))
+	for _, c := range doc.Calendars {
+		byUID[c.UID] = c
+	}
+	current, ok := byUID[doc.CalendarUID]
+	if !ok {
+		for _, c := range doc.Calendars {
+			if c.IsBaseCalendar {
+				current, ok = c, true
+				break
+			}
+		}
+	}
+	cal := workCalendar{
+		weekends: map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays: make(map[time.Time]bool),
+		workdays: make(map[time.Time]bool),
+	}
+	if !ok {
+		return cal, false
+	}
+	// Apply the base calendars first so that the derived ones override them.
+	chain := []xmlCalendar{current}
+	seen := map[int]bool{current.UID: true}
+	for c := current; !c.IsBaseCalendar; {
+		base, found := byUID[c.BaseCalendarUID]
+		if !found || seen[base.UID] {
+			break
+		}
+		seen[base.UID] = true
+		chain = append([]xmlCalendar{base}, chain...)
+		c = base
+	}
+	for _, c := range chain {
+		for _, wd := range c.WeekDays {
+			switch {
+			case wd.DayType >= 1 && wd.DayType <= 7:
+				cal.weekends[time.Weekday(wd.DayType-1)] = !bool(wd.DayWorking)
+			case wd.DayType == 0 && wd.TimePeriod != nil:
+				cal.mark(*wd.TimePeriod, bool(wd.DayWorking))
+			}
+		}
+		for _, ex := range c.Exceptions {
+			if ex.Type == exceptionDaily || ex.Type == 0 {
+				cal.mark(ex.TimePeriod, bool(ex.DayWorking))
+			}
+		}
+	}
+	return cal, true
+}
+
+// mark records every day of the period as a holiday or an extra workday.
+func (c workCalendar) mark(period xmlTimePeriod, working bool) {
+	from, err := parseDate(period.FromDate)
+	if err != nil {
+		return
+	}
+	to, err := parseDate(period.ToDate)
+	if err != nil || to.Before(from) || to.Sub(from) > maxExceptionDays*24*time.Hour {
+		return
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		delete(c.holidays, day)
+		delete(c.workdays, day)
+		if working {
+			c.workdays[day] = true
+		} else {
+			c.holidays[day] = true
+		}
+	}
+}
+
+func (c workCalendar) isWorkday(day time.Time) bool {
+	if c.workdays[day] {
+		return true
+	}
+	return !c.weekends[day.Weekday()] && !c.holidays[day]
+}
+
+func (c workCalendar) nextWorkdayAfter(day time.Time) time.Time {
+	day = day.AddDate(0, 0, 1)
+	for i := 0; i < 366 && !c.isWorkday(day); i++ {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+func (c workCalendar) count(start, end time.Time) int {
+	n := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if c.isWorkday(day) {
+			n++
+		}
+	}
+	return n
+}
+
+// days lists the weekends and the exceptions in date order. Extra workdays that fall on
+// a working weekday and holidays on a weekend are dropped as they change nothing.
+func (c workCalendar) days() (weekends []time.Weekday, holidays, workdays []time.Time) {
+	weekends = []time.Weekday{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if c.weekends[d] {
+			weekends = append(weekends, d)
+		}
+	}
+	for day := range c.holidays {
+		if !c.weekends[day.Weekday()] {
+			holidays = append(holidays, day)
+		}
+	}
+	for day := range c.workdays {
+		if c.weekends[day.Weekday()] {
+			workdays = append(workdays, day)
+		}
+	}
+	sortDates(holidays)
+	sortDates(workdays)
+	return weekends, holidays, workdays
+}
+
+func sortDates(dates []time.Time) {
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+}
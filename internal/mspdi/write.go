@@ -0,0 +1,291 @@
+package mspdi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// Options controls the exported project.
+type Options struct {
+	// Title names the project; empty means "Gantt Chart".
+	Title string
+}
+
+// Write converts scheduled tasks into an MSPDI (Microsoft Project XML) document. Heading
+// rows become summary tasks, so tasks take their outline level from their section.
+// Dependencies become finish-to-start links, and tasks that start later than their
+// dependencies allow (or have none) get a start-no-earlier-than constraint so that Project
+// keeps their dates. Weekends and holidays from the calendar package become the project
+// calendar. Cancelled tasks are written as inactive; display-only rows are left out.
+func Write(tasks []model.Task, opts Options) ([]byte, error) {
+	rows := make([]model.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if !t.DisplayOnly {
+			rows = append(rows, t)
+		}
+	}
+	var (
+		start, finish time.Time
+		scheduled     bool
+	)
+	for _, t := range rows {
+		if t.IsHeading {
+			continue
+		}
+		if !scheduled || t.ComputedStart.Before(start) {
+			start = t.ComputedStart
+		}
+		if !scheduled || t.ComputedEnd.After(finish) {
+			finish = t.ComputedEnd
+		}
+		scheduled = true
+	}
+	if !scheduled {
+		return nil, fmt.Errorf("no schedulable tasks to export")
+	}
+
+	uids := make(map[string]int, len(rows))
+	ends := make(map[string]time.Time, len(rows))
+	for i, t := range rows {
+		if !t.IsHeading {
+			uids[t.Key()] = i + 1
+			ends[t.Key()] = t.ComputedEnd
+		}
+	}
+	levels := outlineLevels(rows)
+	numbers := outlineNumbers(levels)
+	xmlTasks := make([]xmlTask, len(rows))
+	for i, t := range rows {
+		xt := xmlTask{
+			UID:           i + 1,
+			ID:            i + 1,
+			Name:          t.Name,
+			WBS:           numbers[i],
+			OutlineNumber: numbers[i],
+			OutlineLevel:  levels[i],
+			Notes:         t.Notes,
+		}
+		if t.IsHeading {
+			from, to, ok := summarySpan(rows, levels, i)
+			if !ok {
+				from, to = start, start
+			}
+			xt.Summary = true
+			xt.Start = at(from, dayStartTime)
+			xt.Finish = at(to, dayFinishTime)
+			xt.Duration = workDuration(calendar.CountWorkdays(from, to))
+			xt.DurationFormat = durationFormatDays
+			xmlTasks[i] = xt
+			continue
+		}
+		writeTaskDates(&xt, t, ends)
+		for _, dep := range t.DependsOn {
+			if uid, ok := uids[dep]; ok {
+				linkType := linkFinishToStart
+				xt.PredecessorLink = append(xt.PredecessorLink, xmlLink{PredecessorUID: uid, Type: &linkType, LagFormat: durationFormatDays})
+			}
+		}
+		if t.IsCancelled() {
+			inactive := xmlBool(false)
+			xt.Active = &inactive
+		}
+		xmlTasks[i] = xt
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = defaultTitle
+	}
+	fromStart := xmlBool(true)
+	doc := xmlProject{
+		Xmlns:             namespace,
+		SaveVersion:       saveVersion,
+		Name:              title,
+		Title:             title,
+		ScheduleFromStart: &fromStart,
+		StartDate:         at(start, dayStartTime),
+		FinishDate:        at(finish, dayFinishTime),
+		CalendarUID:       calendarUID,
+		DefaultStartTime:  dayStartTime,
+		DefaultFinishTime: dayFinishTime,
+		MinutesPerDay:     hoursPerDay * 60,
+		MinutesPerWeek:    hoursPerDay * 60 * (7 - len(calendar.Weekends())),
+		Calendars:         []xmlCalendar{standardCalendar(start, finish)},
+		Tasks:             xmlTasks,
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode MS Project XML: %w", err)
+	}
+	return append([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"), append(out, '\n')...), nil
+}
+
+// writeTaskDates fills the schedule of a task: dates, duration, constraint, progress and
+// actuals.
+func writeTaskDates(xt *xmlTask, t model.Task, ends map[string]time.Time) {
+	xt.Start = at(t.ComputedStart, dayStartTime)
+	xt.Finish = at(t.ComputedEnd, dayFinishTime)
+	xt.Duration = workDuration(calendar.CountWorkdays(t.ComputedStart, t.ComputedEnd))
+	xt.DurationFormat = durationFormatDays
+	if t.Milestone {
+		// Project places a milestone at the end of the day its predecessors finish, and
+		// its successors start on the next workday, as in the scheduler.
+		xt.Start = at(t.ComputedStart, dayFinishTime)
+		xt.Finish = xt.Start
+		xt.Duration = workDuration(0)
+		xt.Milestone = true
+	}
+	xt.ConstraintType = constraintAsSoonAsPossible
+	if !followsDependencies(t, ends) {
+		xt.ConstraintType = constraintStartNoEarlierThan
+		xt.ConstraintDate = xt.Start
+	}
+
+	done := t.IsCompleted() || (t.ProgressPercent != nil && *t.ProgressPercent >= 100)
+	switch {
+	case done:
+		xt.PercentComplete = 100
+	case t.ProgressPercent != nil:
+		xt.PercentComplete = *t.ProgressPercent
+	}
+	if t.ComputedActualStart != nil {
+		xt.ActualStart = at(*t.ComputedActualStart, dayStartTime)
+		// An actual finish tells Project the task is complete, so it is only written
+		// when the task is done or there is no progress saying otherwise.
+		if t.ComputedActualEnd != nil && (done || t.ProgressPercent == nil) {
+			xt.ActualFinish = at(*t.ComputedActualEnd, dayFinishTime)
+		}
+	}
+}
+
+// followsDependencies reports whether t starts exactly where its dependencies put it,
+// so that Project derives the same start from the links.
+func followsDependencies(t model.Task, ends map[string]time.Time) bool {
+	if len(t.DependsOn) == 0 {
+		return false
+	}
+	var latest time.Time
+	for _, dep := range t.DependsOn {
+		end, ok := ends[dep]
+		if !ok {
+			return false
+		}
+		if end.After(latest) {
+			latest = end
+		}
+	}
+	expected := calendar.NextWorkdayAfter(latest)
+	if t.Milestone {
+		expected = latest
+	}
+	return expected.Equal(calendar.DateOnly(t.ComputedStart))
+}
+
+// outlineLevels returns the outline level of each row: a heading sits one level below the
+// heading before it at most, and tasks sit below the current heading.
+func outlineLevels(rows []model.Task) []int {
+	levels := make([]int, len(rows))
+	section := 0
+	for i, t := range rows {
+		if t.IsHeading {
+			section = min(t.Level+1, section+1)
+			levels[i] = section
+			continue
+		}
+		levels[i] = section + 1
+	}
+	return levels
+}
+
+// outlineNumbers returns outline numbers such as "2.1.3" for the levels.
+func outlineNumbers(levels []int) []string {
+	numbers := make([]string, len(levels))
+	var counters []int
+	for i, level := range levels {
+		counters = append(counters[:min(level, len(counters))], make([]int, max(0, level-len(counters)))...)
+		counters[level-1]++
+		parts := make([]string, level)
+		for j, c := range counters {
+			parts[j] = strconv.Itoa(c)
+		}
+		numbers[i] = strings.Join(parts, ".")
+	}
+	return numbers
+}
+
+// summarySpan returns the dates covered by the tasks under the heading at index i.
+func summarySpan(rows []model.Task, levels []int, i int) (from, to time.Time, ok bool) {
+	for j := i + 1; j < len(rows) && levels[j] > levels[i]; j++ {
+		t := rows[j]
+		if t.IsHeading {
+			continue
+		}
+		if !ok || t.ComputedStart.Before(from) {
+			from = t.ComputedStart
+		}
+		if !ok || t.ComputedEnd.After(to) {
+			to = t.ComputedEnd
+		}
+		ok = true
+	}
+	return from, to, ok
+}
+
+// standardCalendar describes the working days of the calendar package from start to end:
+// the weekly pattern plus an exception for every holiday and extra workday.
+func standardCalendar(start, end time.Time) xmlCalendar {
+	weekends := make(map[time.Weekday]bool)
+	for _, d := range calendar.Weekends() {
+		weekends[d] = true
+	}
+	cal := xmlCalendar{UID: calendarUID, Name: "Standard", IsBaseCalendar: true, BaseCalendarUID: -1}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		day := xmlWeekDay{DayType: int(d) + 1, DayWorking: xmlBool(!weekends[d])}
+		if !weekends[d] {
+			day.WorkingTimes = workingTimes()
+		}
+		cal.WeekDays = append(cal.WeekDays, day)
+	}
+	closed, open := calendar.Exceptions(start, end)
+	for _, d := range closed {
+		cal.Exceptions = append(cal.Exceptions, exception(d, "Holiday", false))
+	}
+	for _, d := range open {
+		cal.Exceptions = append(cal.Exceptions, exception(d, "Workday", true))
+	}
+	return cal
+}
+
+func exception(day time.Time, name string, working bool) xmlException {
+	ex := xmlException{
+		TimePeriod:  xmlTimePeriod{FromDate: at(day, "00:00:00"), ToDate: at(day, "23:59:00")},
+		Occurrences: 1,
+		Name:        name,
+		Type:        exceptionDaily,
+		DayWorking:  xmlBool(working),
+	}
+	if working {
+		ex.WorkingTimes = workingTimes()
+	}
+	return ex
+}
+
+func workingTimes() []xmlWorkingTime {
+	return []xmlWorkingTime{{FromTime: dayStartTime, ToTime: "12:00:00"}, {FromTime: "13:00:00", ToTime: dayFinishTime}}
+}
+
+// at formats the day of t at the given clock time.
+func at(t time.Time, clock string) string {
+	return t.Format("2006-01-02") + "T" + clock
+}
+
+// workDuration formats a number of workdays as an MSPDI duration.
+func workDuration(days int) string {
+	return fmt.Sprintf("PT%dH0M0S", days*hoursPerDay)
+}
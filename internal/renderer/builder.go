@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -12,11 +13,84 @@ import (
 	"ganttgen/internal/model"
 )
 
+// defaultTitle is the page title used when Options.Title is empty.
+const defaultTitle = "Gantt Chart"
+
+// Options controls how BuildHTMLWithOptions renders the chart.
+type Options struct {
+	// LiveReloadURL, when non-empty, injects a small client to auto-refresh the page.
+	LiveReloadURL     string
+	CustomColumns     []string
+	HasProgressColumn bool
+	Title             string
+	// CellWidth is the width of one day column in pixels (default 30).
+	CellWidth int
+	// HideNotes starts the page with the notes column collapsed.
+	HideNotes bool
+	Theme     Theme
+	// From and To limit the chart to a date range; bars outside it are clipped.
+	// Zero values fit the range to the tasks and today.
+	From time.Time
+	To   time.Time
+	// SideColumns selects the columns left of the timeline in image formats (BuildSVG):
+	// "name", "status", "progress", "notes" or a custom column name. Nil draws the name,
+	// status and, when present, progress columns.
+	SideColumns []string
+	// Scale multiplies the pixel size of raster output (BuildPNG); zero means 1.
+	Scale float64
+	// PageSize is the landscape paper size of BuildPDF: "a3" (default) or "a4".
+	PageSize string
+	// ICSEvents selects the events of BuildICS: ICSAllTasks (default) or ICSMilestones.
+	ICSEvents string
+	// TextWidth is the width of BuildText output in terminal columns; zero means
+	// DefaultTextWidth.
+	TextWidth int
+	// TextColor adds ANSI colors to BuildText output.
+	TextColor bool
+}
+
+// Theme overrides chart colors; empty fields keep the built-in palette.
+type Theme struct {
+	Accent            string `yaml:"accent,omitempty"`
+	Accent2           string `yaml:"accent_2,omitempty"`
+	Actual            string `yaml:"actual,omitempty"`
+	Actual2           string `yaml:"actual_2,omitempty"`
+	ProgressRemaining string `yaml:"progress_remaining,omitempty"`
+	Today             string `yaml:"today,omitempty"`
+	Background        string `yaml:"background,omitempty"`
+	Line              string `yaml:"line,omitempty"`
+}
+
 // BuildHTML prepares render data and returns the final HTML string.
 // liveReloadURL, when non-empty, injects a small client to auto-refresh the page.
 func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string, hasProgressColumn bool) (string, error) {
+	return BuildHTMLWithOptions(tasks, Options{
+		LiveReloadURL:     liveReloadURL,
+		CustomColumns:     customColumns,
+		HasProgressColumn: hasProgressColumn,
+	})
+}
+
+// BuildHTMLWithOptions is BuildHTML with title, layout and theme options.
+func BuildHTMLWithOptions(tasks []model.Task, opts Options) (string, error) {
+	themeCSS, err := opts.Theme.css(opts.CellWidth)
+	if err != nil {
+		return "", err
+	}
+	ctx, err := buildContext(tasks, opts)
+	if err != nil {
+		return "", err
+	}
+	ctx.CSS = template.CSS(baseCSS() + themeCSS)
+	return renderHTML(ctx)
+}
+
+// buildContext turns scheduled tasks into the rows and timeline shared by all renderers.
+func buildContext(tasks []model.Task, opts Options) (renderContext, error) {
+	customColumns := opts.CustomColumns
+	hasProgressColumn := opts.HasProgressColumn
 	if len(tasks) == 0 {
-		return "", errors.New("no tasks to render")
+		return renderContext{}, errors.New("no tasks to render")
 	}
 
 	var (
@@ -41,7 +115,7 @@ func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string,
 		}
 	}
 	if !setRange {
-		return "", errors.New("no schedulable tasks to render")
+		return renderContext{}, errors.New("no schedulable tasks to render")
 	}
 	for _, t := range tasks {
 		if t.IsHeading || t.DisplayOnly {
@@ -64,28 +138,48 @@ func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string,
 	if today.After(maxEnd) {
 		maxEnd = today
 	}
+	if !opts.From.IsZero() {
+		minStart = calendar.DateOnly(opts.From)
+	}
+	if !opts.To.IsZero() {
+		maxEnd = calendar.DateOnly(opts.To)
+	}
+	if maxEnd.Before(minStart) {
+		return renderContext{}, fmt.Errorf("date range ends before it starts (%s - %s)", formatDate(minStart), formatDate(maxEnd))
+	}
+	tl := timeline{start: minStart, end: maxEnd}
 
 	days := daysRange(minStart, maxEnd)
 	todayIndex := daysBetween(minStart, today)
+	showToday := !today.Before(minStart) && !today.After(maxEnd)
 
 	var rows []renderRow
+	seriesRows := make(map[string]int) // series key -> index of its row
 	var hasActual bool
+	usedStatuses := make(map[string]bool)
 	var hasNotes bool
 	customCount := len(customColumns)
 	for _, t := range tasks {
 		customValues := padCustomValues(t.CustomValues, customCount)
+		rowID := ""
+		if t.ID != "" {
+			rowID = t.Key()
+		}
 		if t.IsHeading {
 			if t.Notes != "" {
 				hasNotes = true
 			}
 			rows = append(rows, renderRow{
+				ID:             rowID,
 				Heading:        t.Name,
+				HeadingLevel:   t.Level,
 				HeadingStatus:  t.Status,
 				HeadingNotes:   t.Notes,
 				HeadingMuted:   t.IsCancelled() || t.IsCompleted(),
 				CustomValues:   customValues,
 				FilterName:     t.Name,
 				FilterStatus:   t.Status,
+				FilterSemantic: t.Semantic().Label(),
 				FilterProgress: "",
 				FilterNotes:    t.Notes,
 			})
@@ -96,6 +190,7 @@ func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string,
 				hasNotes = true
 			}
 			rows = append(rows, renderRow{
+				ID:               rowID,
 				DisplayOnly:      t.Name,
 				DisplayOnlyNotes: t.Notes,
 				CustomValues:     customValues,
@@ -106,8 +201,31 @@ func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string,
 			})
 			continue
 		}
-		startIdx := daysBetween(minStart, t.ComputedStart)
-		span := daysBetween(t.ComputedStart, t.ComputedEnd) + 1
+		var plans []renderBar
+		for _, seg := range t.PlanSegments() {
+			if bar, ok := tl.bar(seg.Start, seg.End); ok {
+				plans = append(plans, bar)
+			}
+		}
+		if series := t.SeriesKey(); series != "" {
+			for i := range plans {
+				plans[i].Label = fmt.Sprintf("#%d", t.Occurrence.Index)
+			}
+			if i, ok := seriesRows[series]; ok {
+				// Occurrences of a recurring task share the row of the first one.
+				rows[i].Task.Plans = append(rows[i].Task.Plans, plans...)
+				continue
+			}
+			seriesRows[series] = len(rows)
+			rowID = ""
+			if t.Occurrence.SeriesID != "" {
+				rowID = series
+			}
+		}
+		name := t.Name
+		if t.Occurrence != nil {
+			name = t.Occurrence.SeriesName
+		}
 		progressText := ""
 		hasProgress := false
 		progressPercent := 0
@@ -117,43 +235,52 @@ func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string,
 			progressText = fmt.Sprintf("%d%%", progressPercent)
 		}
 		rt := renderTask{
-			Name:            t.Name,
+			Name:            name,
 			Status:          t.Status,
 			Notes:           t.Notes,
 			Cancelled:       t.IsCancelled() || t.IsCompleted(),
+			Blocked:         t.Semantic() == model.SemanticBlocked,
+			Milestone:       t.Milestone,
 			HasProgress:     hasProgress,
 			ProgressPercent: progressPercent,
 			ProgressText:    progressText,
-			StartIndex:      startIdx,
-			Span:            span,
-			Start:           calendar.DateOnly(t.ComputedStart),
-			End:             calendar.DateOnly(t.ComputedEnd),
+			Plans:           plans,
 		}
-		if t.HasActual() {
+		if e := t.Effort; e != nil {
+			rt.PlanHours = formatHours(e.PlannedHours)
+			rt.ActualHours = formatHours(e.ActualHours)
+			rt.People = strings.Join(e.People, ", ")
+			rt.ProgressEstimated = e.ProgressEstimated
+		}
+		if def, ok := model.LookupStatus(t.Status); ok && def.Color != "" {
+			// Colors are validated when the vocabulary is registered.
+			rt.StatusColor = template.CSS(def.Color)
+			usedStatuses[def.Name] = true
+		}
+		for _, seg := range t.ActualIntervals() {
 			hasActual = true
-			actualStartIdx := daysBetween(minStart, *t.ComputedActualStart)
-			actualSpan := daysBetween(*t.ComputedActualStart, *t.ComputedActualEnd) + 1
-			rt.Actual = &renderActual{
-				StartIndex: actualStartIdx,
-				Span:       actualSpan,
-				Start:      calendar.DateOnly(*t.ComputedActualStart),
-				End:        calendar.DateOnly(*t.ComputedActualEnd),
+			rt.ActualDays += calendar.CountWorkdays(seg.Start, seg.End)
+			if bar, ok := tl.bar(seg.Start, seg.End); ok {
+				rt.Actuals = append(rt.Actuals, bar)
 			}
 		}
 		if t.Notes != "" {
 			hasNotes = true
 		}
 		rows = append(rows, renderRow{
+			ID:             rowID,
 			Task:           &rt,
 			CustomValues:   customValues,
-			FilterName:     t.Name,
+			FilterName:     name,
 			FilterStatus:   t.Status,
+			FilterSemantic: t.Semantic().Label(),
 			FilterProgress: progressText,
 			FilterNotes:    t.Notes,
 		})
 	}
 
-	filterColumns := buildFilterColumns(rows, hasNotes, hasProgressColumn, customColumns)
+	hasSemantic := len(model.Statuses()) > 0
+	filterColumns := buildFilterColumns(rows, hasNotes, hasProgressColumn, hasSemantic, customColumns)
 	bodyClasses := []string{}
 	if customCount > 0 {
 		bodyClasses = append(bodyClasses, "has-custom")
@@ -161,24 +288,102 @@ func BuildHTML(tasks []model.Task, liveReloadURL string, customColumns []string,
 	if hasProgressColumn {
 		bodyClasses = append(bodyClasses, "has-progress")
 	}
+	notesHidden := opts.HideNotes && hasNotes
+	if notesHidden {
+		bodyClasses = append(bodyClasses, "notes-hidden")
+	}
+	title := opts.Title
+	if title == "" {
+		title = defaultTitle
+	}
 
 	ctx := renderContext{
+		Title:             title,
 		Days:              days,
 		Rows:              rows,
 		DayCount:          len(days),
 		TodayIndex:        todayIndex,
+		ShowToday:         showToday,
 		HasActual:         hasActual,
+		StatusLegend:      buildStatusLegend(usedStatuses),
 		HasNotes:          hasNotes,
+		NotesHidden:       notesHidden,
 		HasProgress:       hasProgressColumn,
 		HasCustomColumns:  customCount > 0,
 		CustomColumns:     customColumns,
 		CustomColumnCount: customCount,
 		FilterColumns:     filterColumns,
 		BodyClass:         strings.Join(bodyClasses, " "),
-		LiveReloadURL:     liveReloadURL,
-		CSS:               template.CSS(baseCSS()),
+		LiveReloadURL:     opts.LiveReloadURL,
 	}
-	return renderHTML(ctx)
+	return ctx, nil
+}
+
+// css returns a :root block overriding the palette and cell width, or "" when nothing is set.
+func (th Theme) css(cellWidth int) (string, error) {
+	vars := []struct{ name, value string }{
+		{"--accent", th.Accent},
+		{"--accent-2", th.Accent2},
+		{"--actual", th.Actual},
+		{"--actual-2", th.Actual2},
+		{"--progress-remaining", th.ProgressRemaining},
+		{"--today", th.Today},
+		{"--bg", th.Background},
+		{"--line", th.Line},
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		if v.value == "" {
+			continue
+		}
+		if !model.IsValidColor(v.value) {
+			return "", fmt.Errorf("theme: invalid color %q for %s", v.value, strings.TrimPrefix(v.name, "--"))
+		}
+		fmt.Fprintf(&b, "  %s: %s;\n", v.name, v.value)
+	}
+	if cellWidth < 0 {
+		return "", fmt.Errorf("cell width must be positive, got %d", cellWidth)
+	}
+	if cellWidth > 0 {
+		fmt.Fprintf(&b, "  --cell-width: %dpx;\n", cellWidth)
+	}
+	if b.Len() == 0 {
+		return "", nil
+	}
+	return "\n:root {\n" + b.String() + "}\n", nil
+}
+
+// formatHours renders hours with at most one decimal ("40h", "7.5h").
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(math.Round(hours*10)/10, 'f', -1, 64) + "h"
+}
+
+// timeline is the inclusive date range shown by the chart.
+type timeline struct {
+	start time.Time
+	end   time.Time
+}
+
+// bar places an interval on the timeline, clipping it to the visible range. It reports
+// false when the interval lies entirely outside. Start and End keep the unclipped dates.
+func (tl timeline) bar(start, end time.Time) (renderBar, bool) {
+	start, end = calendar.DateOnly(start), calendar.DateOnly(end)
+	if end.Before(tl.start) || start.After(tl.end) {
+		return renderBar{}, false
+	}
+	from, to := start, end
+	if from.Before(tl.start) {
+		from = tl.start
+	}
+	if to.After(tl.end) {
+		to = tl.end
+	}
+	return renderBar{
+		StartIndex: daysBetween(tl.start, from),
+		Span:       daysBetween(from, to) + 1,
+		Start:      start,
+		End:        end,
+	}, true
 }
 
 func daysRange(start, end time.Time) []time.Time {
@@ -209,9 +414,22 @@ func padCustomValues(values []string, count int) []string {
 	return padded
 }
 
-func buildFilterColumns(rows []renderRow, hasNotes bool, hasProgress bool, customColumns []string) []filterColumn {
+// buildStatusLegend lists the colored statuses in use, in vocabulary order.
+func buildStatusLegend(used map[string]bool) []statusLegend {
+	var legend []statusLegend
+	for _, def := range model.Statuses() {
+		if def.Color == "" || !used[def.Name] {
+			continue
+		}
+		legend = append(legend, statusLegend{Name: def.Name, Color: template.CSS(def.Color)})
+	}
+	return legend
+}
+
+func buildFilterColumns(rows []renderRow, hasNotes bool, hasProgress bool, hasSemantic bool, customColumns []string) []filterColumn {
 	names := make([]string, 0, len(rows))
 	statuses := make([]string, 0, len(rows))
+	semantics := make([]string, 0, len(rows))
 	progresses := make([]string, 0, len(rows))
 	notes := make([]string, 0, len(rows))
 	customValues := make([][]string, len(customColumns))
@@ -219,6 +437,7 @@ func buildFilterColumns(rows []renderRow, hasNotes bool, hasProgress bool, custo
 	for _, row := range rows {
 		names = append(names, row.FilterName)
 		statuses = append(statuses, row.FilterStatus)
+		semantics = append(semantics, row.FilterSemantic)
 		if hasProgress {
 			progresses = append(progresses, row.FilterProgress)
 		}
@@ -236,6 +455,9 @@ func buildFilterColumns(rows []renderRow, hasNotes bool, hasProgress bool, custo
 		{Key: "name", Label: "Task", Values: uniqueValues(names)},
 		{Key: "status", Label: "状態", Values: uniqueValues(statuses)},
 	}
+	if hasSemantic {
+		filterColumns = append(filterColumns, filterColumn{Key: "semantic", Label: "区分", Values: semanticValues(semantics)})
+	}
 	if hasProgress {
 		filterColumns = append(filterColumns, filterColumn{Key: "progress", Label: "進捗", Values: uniqueValues(progresses)})
 	}
@@ -274,23 +496,61 @@ func uniqueValues(values []string) []string {
 	return res
 }
 
+// semanticValues orders semantic labels like model.Semantics rather than by appearance.
+func semanticValues(values []string) []string {
+	present := make(map[string]bool, len(values))
+	for _, v := range values {
+		present[v] = true
+	}
+	var res []string
+	for _, s := range model.Semantics {
+		if present[s.Label()] {
+			res = append(res, s.Label())
+		}
+	}
+	if present[""] {
+		res = append(res, "")
+	}
+	return res
+}
+
 type renderTask struct {
 	Name            string
 	Status          string
 	Notes           string
 	Cancelled       bool
+	Blocked         bool
+	Milestone       bool
+	StatusColor     template.CSS
 	HasProgress     bool
 	ProgressPercent int
 	ProgressText    string
-	StartIndex      int
-	Span            int
-	Start           time.Time
-	End             time.Time
-	Actual          *renderActual
+	// Plans holds one bar per planned interval: paused tasks have one per segment and
+	// recurring tasks one per occurrence.
+	Plans []renderBar
+	// Actuals holds one bar per actual work period.
+	Actuals    []renderBar
+	ActualDays int
+	// PlanHours and ActualHours compare the effort when a timesheet was applied.
+	PlanHours         string
+	ActualHours       string
+	People            string
+	ProgressEstimated bool
+}
+
+type renderBar struct {
+	StartIndex int
+	Span       int
+	Start      time.Time
+	End        time.Time
+	// Label identifies the bar within its row in the tooltip (e.g. "#3").
+	Label string
 }
 
 type renderRow struct {
+	ID               string
 	Heading          string
+	HeadingLevel     int
 	HeadingStatus    string
 	HeadingNotes     string
 	HeadingMuted     bool
@@ -300,24 +560,22 @@ type renderRow struct {
 	CustomValues     []string
 	FilterName       string
 	FilterStatus     string
+	FilterSemantic   string
 	FilterProgress   string
 	FilterNotes      string
 }
 
-type renderActual struct {
-	StartIndex int
-	Span       int
-	Start      time.Time
-	End        time.Time
-}
-
 type renderContext struct {
+	Title             string
 	Days              []time.Time
 	Rows              []renderRow
 	DayCount          int
 	TodayIndex        int
+	ShowToday         bool
 	HasActual         bool
+	StatusLegend      []statusLegend
 	HasNotes          bool
+	NotesHidden       bool
 	HasProgress       bool
 	HasCustomColumns  bool
 	CustomColumns     []string
@@ -333,3 +591,8 @@ type filterColumn struct {
 	Label  string
 	Values []string
 }
+
+type statusLegend struct {
+	Name  string
+	Color template.CSS
+}
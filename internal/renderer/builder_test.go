@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -107,6 +108,202 @@ func TestBuildHTMLRendersProgressColumn(t *testing.T) {
 	}
 }
 
+func TestBuildHTMLRendersTaskIDs(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "T-1", Name: "Task A", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)},
+	}
+
+	html, err := BuildHTML(tasks, "", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(html, `data-id="T-1"`) {
+		t.Fatalf("task id attribute not rendered")
+	}
+}
+
+func TestBuildHTMLMergesRecurringTasksIntoOneRow(t *testing.T) {
+	var tasks []model.Task
+	for i, d := range []int{3, 10, 17} {
+		tasks = append(tasks, model.Task{
+			ID:            fmt.Sprintf("std#%d", i+1),
+			Name:          fmt.Sprintf("Standup #%d", i+1),
+			ComputedStart: day(2024, time.June, d),
+			ComputedEnd:   day(2024, time.June, d),
+			Occurrence:    &model.Occurrence{SeriesID: "std", SeriesName: "Standup", Index: i + 1, Count: 3},
+		})
+	}
+
+	html, err := BuildHTML(tasks, "", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(html, `data-id="std"`) {
+		t.Fatalf("series row id not rendered")
+	}
+	if strings.Contains(html, "Standup #2") {
+		t.Fatalf("occurrence names should not be rendered as rows")
+	}
+	for _, want := range []string{"grid-column:1 / span 1", "grid-column:8 / span 1", "grid-column:15 / span 1", "予定 #3: "} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in output", want)
+		}
+	}
+}
+
+func TestBuildHTMLRendersPausedTaskSegments(t *testing.T) {
+	tasks := []model.Task{
+		{
+			Name:          "Build",
+			ComputedStart: day(2024, time.June, 3),
+			ComputedEnd:   day(2024, time.June, 14),
+			ComputedSegments: []model.Interval{
+				{Start: day(2024, time.June, 3), End: day(2024, time.June, 4)},
+				{Start: day(2024, time.June, 12), End: day(2024, time.June, 14)},
+			},
+		},
+	}
+
+	html, err := BuildHTML(tasks, "", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(html, "grid-column:1 / span 2") || !strings.Contains(html, "grid-column:10 / span 3") {
+		t.Fatalf("expected one bar per segment")
+	}
+	if strings.Contains(html, "grid-column:1 / span 12") {
+		t.Fatalf("paused task should not render a single bar")
+	}
+}
+
+func TestBuildHTMLRendersActualSegments(t *testing.T) {
+	tasks := []model.Task{
+		{
+			Name:                "Build",
+			ComputedStart:       day(2024, time.June, 3),
+			ComputedEnd:         day(2024, time.June, 12),
+			ComputedActualStart: ptrTime(day(2024, time.June, 3)),
+			ComputedActualEnd:   ptrTime(day(2024, time.June, 11)),
+			ActualSegments: []model.Interval{
+				{Start: day(2024, time.June, 3), End: day(2024, time.June, 5)},
+				{Start: day(2024, time.June, 10), End: day(2024, time.June, 11)},
+			},
+		},
+	}
+
+	html, err := BuildHTML(tasks, "", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"grid-column:1 / span 3", "grid-column:8 / span 2", "(計 5 日)"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in output", want)
+		}
+	}
+}
+
+func TestBuildHTMLRendersEffort(t *testing.T) {
+	progress := 45
+	tasks := []model.Task{
+		{
+			Name:                "Build",
+			ProgressPercent:     &progress,
+			ComputedStart:       day(2024, time.June, 3),
+			ComputedEnd:         day(2024, time.June, 7),
+			ComputedActualStart: ptrTime(day(2024, time.June, 3)),
+			ComputedActualEnd:   ptrTime(day(2024, time.June, 4)),
+			Effort:              &model.Effort{PlannedHours: 40, ActualHours: 18.25, People: []string{"Sato"}, ProgressEstimated: true},
+		},
+	}
+
+	html, err := BuildHTML(tasks, "", nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"予定 40h", "実績 18.3h", "工数: 予定 40h / 実績 18.3h", "工数から推定", "(Sato)"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in output", want)
+		}
+	}
+}
+
+func TestBuildHTMLRendersHeadingLevels(t *testing.T) {
+	tasks := []model.Task{
+		{Name: "backend.csv", IsHeading: true},
+		{Name: "API", IsHeading: true, Level: 1},
+		{Name: "Task A", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)},
+	}
+
+	html, err := BuildHTML(tasks, "", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(html, `data-heading="true" data-level="0" data-name="backend.csv"`) {
+		t.Fatalf("top-level heading not rendered")
+	}
+	if !strings.Contains(html, `data-heading="true" data-level="1" data-name="API"`) {
+		t.Fatalf("nested heading level not rendered")
+	}
+}
+
+func TestBuildHTMLRendersStatusColors(t *testing.T) {
+	if err := model.SetStatuses([]model.StatusDef{
+		{Name: "進行中", Color: "#2563eb", Semantic: model.SemanticInProgress},
+		{Name: "保留", Color: "#eab308", Semantic: model.SemanticBlocked},
+		{Name: "未着手", Color: "#9ca3af", Semantic: model.SemanticNotStarted},
+	}); err != nil {
+		t.Fatalf("set statuses: %v", err)
+	}
+	t.Cleanup(func() { model.SetStatuses(nil) })
+
+	tasks := []model.Task{
+		{Name: "Task A", Status: "進行中", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 4)},
+		{Name: "Task B", Status: "保留", ComputedStart: day(2024, time.June, 5), ComputedEnd: day(2024, time.June, 6)},
+	}
+
+	html, err := BuildHTML(tasks, "", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(html, "--status-color:#2563eb;") {
+		t.Fatalf("status color not applied to bar")
+	}
+	if !strings.Contains(html, "status-colored blocked") {
+		t.Fatalf("blocked status not marked")
+	}
+	if !strings.Contains(html, `data-semantic="保留"`) || !strings.Contains(html, `data-filter="semantic"`) {
+		t.Fatalf("semantic filter not rendered")
+	}
+	if strings.Contains(html, "<span>未着手</span>") {
+		t.Fatalf("unused status should not appear in the legend")
+	}
+}
+
+func TestBuildHTMLWithOptionsAppliesTitleAndTheme(t *testing.T) {
+	tasks := []model.Task{
+		{Name: "Task A", Notes: "memo", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)},
+	}
+
+	html, err := BuildHTMLWithOptions(tasks, Options{
+		Title:     "Release plan",
+		CellWidth: 24,
+		HideNotes: true,
+		Theme:     Theme{Accent: "#0f766e"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"<title>Release plan</title>", "--accent: #0f766e;", "--cell-width: 24px;", "notes-hidden", "備考を表示"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in output", want)
+		}
+	}
+
+	if _, err := BuildHTMLWithOptions(tasks, Options{Theme: Theme{Today: "red;}"}}); err == nil {
+		t.Fatalf("expected invalid theme color to be rejected")
+	}
+}
+
 func day(y int, m time.Month, d int) time.Time {
 	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
 }
@@ -0,0 +1,482 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+)
+
+// defaultTheme is the built-in palette; it matches the variables in baseCSS.
+var defaultTheme = Theme{
+	Accent:            "#4c6fff",
+	Accent2:           "#67b4ff",
+	Actual:            "#f97316",
+	Actual2:           "#fdba74",
+	ProgressRemaining: "#ef4444",
+	Today:             "#ff5a5f",
+	Background:        "#f5f7fb",
+	Line:              "#e0e5ef",
+}
+
+// Chart geometry in pixels (before any output scaling).
+const (
+	chartPadding       = 16
+	chartTitleHeight   = 36
+	chartMonthHeight   = 20
+	chartDayHeight     = 18
+	chartWeekHeight    = 16
+	chartRowHeight     = 28
+	chartActualRow     = 40 // row height when actual bars are drawn below the plan
+	chartFontSize      = 12
+	chartTitleSize     = 18
+	chartColumnPad     = 8
+	chartMinColumn     = 48
+	chartMaxName       = 260
+	chartMaxColumn     = 160
+	chartHeadingIndent = 12
+)
+
+const (
+	chartText       = "#0f172a"
+	chartMutedText  = "#6b7280"
+	chartWeekend    = "#eef1f6"
+	chartHeadingBg  = "#f7f7ff"
+	chartCancelled  = "#f1f3f6"
+	chartBlocked    = "#b91c1c"
+	chartPanel      = "#ffffff"
+	chartFontFamily = "'Hiragino Sans', 'Noto Sans CJK JP', 'Yu Gothic', Meiryo, sans-serif"
+)
+
+var weekdayLabels = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
+// markKind is the primitive a mark draws.
+type markKind int
+
+const (
+	markRect markKind = iota
+	markLine
+	markText
+)
+
+// mark is one drawing primitive of a chart. Image formats draw the marks in order.
+type mark struct {
+	Kind markKind
+	// Rect: X, Y, W, H. Line: from (X, Y) to (X+W, Y+H). Text: baseline at (X, Y).
+	X, Y, W, H float64
+	Fill       string
+	Stroke     string
+	// StrokeWidth defaults to 1.
+	StrokeWidth float64
+	Dashed      bool
+	Radius      float64
+	Opacity     float64 // 0 means opaque
+	Text        string
+	FontSize    float64
+	Bold        bool
+	Anchor      string // "start", "middle" or "end"
+	// Title is a tooltip for formats that support one.
+	Title string
+}
+
+// chart is a laid-out Gantt chart: its size and the marks that draw it.
+type chart struct {
+	Width  float64
+	Height float64
+	Marks  []mark
+}
+
+// sideColumn is a column drawn left of the timeline.
+type sideColumn struct {
+	label string
+	width float64
+	value func(renderRow) string
+}
+
+// sideColumnNames lists the built-in side columns for error messages.
+var sideColumnNames = []string{"name", "status", "progress", "notes"}
+
+// resolveSideColumns returns the side columns selected by names, or the defaults when
+// names is nil.
+func resolveSideColumns(ctx renderContext, names []string) ([]sideColumn, error) {
+	if names == nil {
+		names = []string{"name", "status"}
+		if ctx.HasProgress {
+			names = append(names, "progress")
+		}
+	}
+	var cols []sideColumn
+	for _, raw := range names {
+		name := strings.TrimSpace(raw)
+		var col sideColumn
+		switch strings.ToLower(name) {
+		case "":
+			continue
+		case "name":
+			col = sideColumn{label: "Task", value: func(r renderRow) string {
+				switch {
+				case r.Task != nil:
+					return r.Task.Name
+				case r.Heading != "":
+					return r.Heading
+				}
+				return r.DisplayOnly
+			}}
+		case "status":
+			col = sideColumn{label: "状態", value: func(r renderRow) string {
+				if r.Task != nil {
+					return r.Task.Status
+				}
+				return r.HeadingStatus
+			}}
+		case "progress":
+			col = sideColumn{label: "進捗", value: func(r renderRow) string {
+				if r.Task != nil {
+					return r.Task.ProgressText
+				}
+				return ""
+			}}
+		case "notes":
+			col = sideColumn{label: "備考", value: func(r renderRow) string {
+				switch {
+				case r.Task != nil:
+					return r.Task.Notes
+				case r.Heading != "":
+					return r.HeadingNotes
+				}
+				return r.DisplayOnlyNotes
+			}}
+		default:
+			idx := -1
+			for i, custom := range ctx.CustomColumns {
+				if custom == name {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				return nil, fmt.Errorf("unknown side column %q (use %s or a custom column name)", name, strings.Join(sideColumnNames, ", "))
+			}
+			col = sideColumn{label: name, value: func(r renderRow) string {
+				if idx < len(r.CustomValues) {
+					return r.CustomValues[idx]
+				}
+				return ""
+			}}
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// palette returns the theme with empty colors filled from defaultTheme.
+func (th Theme) palette() (Theme, error) {
+	if _, err := th.css(0); err != nil {
+		return Theme{}, err
+	}
+	pick := func(v, def string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	}
+	return Theme{
+		Accent:            pick(th.Accent, defaultTheme.Accent),
+		Accent2:           pick(th.Accent2, defaultTheme.Accent2),
+		Actual:            pick(th.Actual, defaultTheme.Actual),
+		Actual2:           pick(th.Actual2, defaultTheme.Actual2),
+		ProgressRemaining: pick(th.ProgressRemaining, defaultTheme.ProgressRemaining),
+		Today:             pick(th.Today, defaultTheme.Today),
+		Background:        pick(th.Background, defaultTheme.Background),
+		Line:              pick(th.Line, defaultTheme.Line),
+	}, nil
+}
+
+// textWidth estimates the rendered width of s: full-width (CJK) characters take one em,
+// everything else about 0.6 em.
+func textWidth(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			w += size
+		} else {
+			w += size * 0.6
+		}
+	}
+	return w
+}
+
+// truncateText shortens s with an ellipsis so that it fits in width.
+func truncateText(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	limit := width - textWidth("…", size)
+	w := 0.0
+	for i, r := range s {
+		rw := textWidth(string(r), size)
+		if w+rw > limit {
+			return s[:i] + "…"
+		}
+		w += rw
+	}
+	return s
+}
+
+// chartStyle holds the resolved palette, day width and side columns of a chart. Paged
+// output lays out every page with the same style so that the columns line up.
+type chartStyle struct {
+	theme Theme
+	cell  float64
+	cols  []sideColumn
+}
+
+// newChartStyle resolves opts and sizes the side columns to fit the values in ctx.
+func newChartStyle(ctx renderContext, opts Options) (chartStyle, error) {
+	th, err := opts.Theme.palette()
+	if err != nil {
+		return chartStyle{}, err
+	}
+	if opts.CellWidth < 0 {
+		return chartStyle{}, fmt.Errorf("cell width must be positive, got %d", opts.CellWidth)
+	}
+	cell := float64(opts.CellWidth)
+	if cell == 0 {
+		cell = 30
+	}
+	cols, err := resolveSideColumns(ctx, opts.SideColumns)
+	if err != nil {
+		return chartStyle{}, err
+	}
+	for i := range cols {
+		limit := float64(chartMaxColumn)
+		if i == 0 {
+			limit = chartMaxName
+		}
+		w := textWidth(cols[i].label, chartFontSize)
+		for _, row := range ctx.Rows {
+			extra := 0.0
+			if row.Heading != "" && i == 0 {
+				extra = float64(row.HeadingLevel) * chartHeadingIndent
+			}
+			w = max(w, textWidth(cols[i].value(row), chartFontSize)+extra)
+		}
+		cols[i].width = min(max(w+2*chartColumnPad, chartMinColumn), limit)
+	}
+	return chartStyle{theme: th, cell: cell, cols: cols}, nil
+}
+
+// sideWidth is the total width of the side columns.
+func (st chartStyle) sideWidth() float64 {
+	w := 0.0
+	for _, c := range st.cols {
+		w += c.width
+	}
+	return w
+}
+
+// rowHeight is the height of one row; rows are taller when actual bars are drawn.
+func rowHeight(ctx renderContext) float64 {
+	if ctx.HasActual {
+		return chartActualRow
+	}
+	return chartRowHeight
+}
+
+// chartHeaderHeight is the height above the first row: padding, title and date header.
+const chartHeaderHeight = chartPadding + chartTitleHeight + chartMonthHeight + chartDayHeight + chartWeekHeight
+
+// layoutChart lays out the chart for image formats from the render context.
+func layoutChart(ctx renderContext, opts Options) (chart, error) {
+	st, err := newChartStyle(ctx, opts)
+	if err != nil {
+		return chart{}, err
+	}
+	return st.layout(ctx), nil
+}
+
+// layout draws ctx with the style.
+func (st chartStyle) layout(ctx renderContext) chart {
+	th, cell, cols := st.theme, st.cell, st.cols
+	rowHeight := rowHeight(ctx)
+	sideWidth := st.sideWidth()
+	left := chartPadding + sideWidth
+	top := float64(chartPadding + chartTitleHeight)
+	bodyTop := float64(chartHeaderHeight)
+	timelineWidth := float64(len(ctx.Days)) * cell
+	c := chart{
+		Width:  left + timelineWidth + chartPadding,
+		Height: bodyTop + float64(len(ctx.Rows))*rowHeight + chartPadding,
+	}
+	bodyBottom := c.Height - chartPadding
+	right := left + timelineWidth
+	add := func(m mark) { c.Marks = append(c.Marks, m) }
+	text := func(x, y float64, s string, opts mark) {
+		opts.Kind, opts.X, opts.Y, opts.Text = markText, x, y, s
+		if opts.FontSize == 0 {
+			opts.FontSize = chartFontSize
+		}
+		if opts.Fill == "" {
+			opts.Fill = chartText
+		}
+		if opts.Anchor == "" {
+			opts.Anchor = "start"
+		}
+		add(opts)
+	}
+	hline := func(x1, x2, y float64) { add(mark{Kind: markLine, X: x1, Y: y, W: x2 - x1, Stroke: th.Line}) }
+	vline := func(x, y1, y2 float64) { add(mark{Kind: markLine, X: x, Y: y1, H: y2 - y1, Stroke: th.Line}) }
+
+	add(mark{Kind: markRect, W: c.Width, H: c.Height, Fill: th.Background})
+	add(mark{Kind: markRect, X: chartPadding, Y: top, W: right - chartPadding, H: bodyBottom - top, Fill: chartPanel, Stroke: th.Line})
+	text(chartPadding, chartPadding+chartTitleSize+4, ctx.Title, mark{FontSize: chartTitleSize, Bold: true})
+
+	// Timeline header and weekend shading.
+	for i, d := range ctx.Days {
+		x := left + float64(i)*cell
+		if !calendar.IsWorkday(d) {
+			add(mark{Kind: markRect, X: x, Y: top + chartMonthHeight, W: cell, H: bodyBottom - top - chartMonthHeight, Fill: chartWeekend})
+		}
+		dayColor := chartText
+		if !calendar.IsWorkday(d) {
+			dayColor = chartMutedText
+		}
+		text(x+cell/2, top+chartMonthHeight+chartDayHeight-5, fmt.Sprint(d.Day()), mark{Anchor: "middle", Fill: dayColor, FontSize: chartFontSize - 1})
+		text(x+cell/2, bodyTop-4, weekdayLabels[d.Weekday()], mark{Anchor: "middle", Fill: dayColor, FontSize: chartFontSize - 2})
+		if i == 0 || d.Day() == 1 {
+			label := d.Format("2006-01")
+			if remaining := daysLeftInMonth(d, len(ctx.Days)-i); float64(remaining)*cell >= textWidth(label, chartFontSize) {
+				text(x+4, top+chartMonthHeight-6, label, mark{Bold: true})
+			}
+			if i > 0 {
+				vline(x, top, bodyBottom)
+			}
+		}
+	}
+	hline(chartPadding, right, top+chartMonthHeight)
+	hline(chartPadding, right, bodyTop)
+	vline(left, top, bodyBottom)
+
+	// Side column headers.
+	x := float64(chartPadding)
+	for i, col := range cols {
+		if i > 0 {
+			vline(x, top, bodyBottom)
+		}
+		text(x+chartColumnPad, bodyTop-6, col.label, mark{Bold: true})
+		x += col.width
+	}
+
+	// Rows.
+	for i, row := range ctx.Rows {
+		y := bodyTop + float64(i)*rowHeight
+		baseline := y + rowHeight/2 + chartFontSize*0.35
+		muted := row.HeadingMuted || (row.Task != nil && row.Task.Cancelled)
+		switch {
+		case row.Heading != "":
+			add(mark{Kind: markRect, X: chartPadding, Y: y, W: right - chartPadding, H: rowHeight, Fill: chartHeadingBg})
+		case muted:
+			add(mark{Kind: markRect, X: chartPadding, Y: y, W: right - chartPadding, H: rowHeight, Fill: chartCancelled})
+		}
+		textColor := chartText
+		if muted {
+			textColor = chartMutedText
+		}
+		x := float64(chartPadding)
+		for ci, col := range cols {
+			indent := 0.0
+			if ci == 0 && row.Heading != "" {
+				indent = float64(row.HeadingLevel) * chartHeadingIndent
+			}
+			if v := col.value(row); v != "" {
+				avail := col.width - 2*chartColumnPad - indent
+				text(x+chartColumnPad+indent, baseline, truncateText(v, chartFontSize, avail), mark{Fill: textColor, Bold: row.Heading != "", Title: v})
+			}
+			x += col.width
+		}
+		if row.Task != nil {
+			layoutBars(&c, row.Task, th, left, y, cell, ctx.HasActual)
+		}
+		hline(chartPadding, right, y+rowHeight)
+	}
+
+	if ctx.ShowToday {
+		tx := left + (float64(ctx.TodayIndex)+0.5)*cell
+		add(mark{Kind: markLine, X: tx, Y: top + chartMonthHeight, H: bodyBottom - top - chartMonthHeight, Stroke: th.Today, StrokeWidth: 2, Title: "今日"})
+	}
+	return c
+}
+
+// layoutBars adds the plan and actual bars of one task row.
+func layoutBars(c *chart, t *renderTask, th Theme, left, y, cell float64, hasActual bool) {
+	opacity := 0.0
+	if t.Cancelled {
+		opacity = 0.45
+	}
+	planY, planH := y+6, float64(chartRowHeight-12)
+	if hasActual {
+		planY, planH = y+5, 14
+	}
+	fill := th.Accent
+	if t.StatusColor != "" {
+		fill = string(t.StatusColor)
+	}
+	for _, b := range t.Plans {
+		x, w := left+float64(b.StartIndex)*cell+1, float64(b.Span)*cell-2
+		title := planTitle(t, b)
+		if t.HasProgress {
+			done := w * float64(min(max(t.ProgressPercent, 0), 100)) / 100
+			c.Marks = append(c.Marks, mark{Kind: markRect, X: x, Y: planY, W: w, H: planH, Fill: th.ProgressRemaining, Radius: 4, Opacity: opacity, Title: title})
+			if done > 0 {
+				c.Marks = append(c.Marks, mark{Kind: markRect, X: x, Y: planY, W: done, H: planH, Fill: fill, Radius: 4, Opacity: opacity, Title: title})
+			}
+		} else {
+			c.Marks = append(c.Marks, mark{Kind: markRect, X: x, Y: planY, W: w, H: planH, Fill: fill, Radius: 4, Opacity: opacity, Title: title})
+		}
+		if t.Blocked {
+			c.Marks = append(c.Marks, mark{Kind: markRect, X: x - 1, Y: planY - 1, W: w + 2, H: planH + 2, Stroke: chartBlocked, StrokeWidth: 1.5, Dashed: true, Radius: 5})
+		}
+	}
+	multiple := len(t.Actuals) > 1
+	for _, b := range t.Actuals {
+		x, w := left+float64(b.StartIndex)*cell+1, float64(b.Span)*cell-2
+		title := fmt.Sprintf("実績: %s - %s", formatDate(b.Start), formatDate(b.End))
+		if multiple {
+			title += fmt.Sprintf(" (計 %d 日)", t.ActualDays)
+		}
+		if t.ActualHours != "" {
+			title += " 工数: " + t.ActualHours
+		}
+		if t.People != "" {
+			title += " 担当: " + t.People
+		}
+		c.Marks = append(c.Marks, mark{Kind: markRect, X: x, Y: y + 23, W: w, H: 11, Fill: th.Actual, Radius: 3, Opacity: opacity, Title: title})
+	}
+}
+
+// planTitle is the tooltip of a plan bar, worded like the HTML chart.
+func planTitle(t *renderTask, b renderBar) string {
+	title := "予定"
+	if b.Label != "" {
+		title += " " + b.Label
+	}
+	title += fmt.Sprintf(": %s - %s", formatDate(b.Start), formatDate(b.End))
+	if t.HasProgress {
+		title += " (進捗 " + t.ProgressText
+		if t.ProgressEstimated {
+			title += "・工数から推定"
+		}
+		title += ")"
+	}
+	if t.PlanHours != "" {
+		title += fmt.Sprintf(" 工数: 予定 %s / 実績 %s", t.PlanHours, t.ActualHours)
+	}
+	return title
+}
+
+// daysLeftInMonth counts the days from d to the end of its month, at most limit.
+func daysLeftInMonth(d time.Time, limit int) int {
+	next := time.Date(d.Year(), d.Month()+1, 1, 0, 0, 0, 0, d.Location())
+	return min(daysBetween(d, next), limit)
+}
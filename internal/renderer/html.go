@@ -144,6 +144,7 @@ body {
 
 .legend-swatch.plan { background: linear-gradient(135deg, var(--accent), var(--accent-2)); }
 .legend-swatch.actual { background: linear-gradient(135deg, var(--actual), var(--actual-2)); }
+.legend-swatch.status { background: var(--status-color); }
 
 .toggle-notes {
   padding: 8px 12px;
@@ -227,6 +228,17 @@ body {
   align-items: center;
 }
 
+.heading[data-level="1"] {
+  padding-left: 28px;
+  font-weight: 600;
+  background: linear-gradient(120deg, #fff, #fafaff);
+}
+
+.heading[data-level="2"] {
+  padding-left: 44px;
+  font-weight: 600;
+}
+
 .name.header {
   background: linear-gradient(120deg, #fff, #f0f4ff);
   height: var(--timeline-header-height);
@@ -609,7 +621,13 @@ body {
   padding: 0;
 }
 
+/* Plan segments share the first line of a row and actual periods the second. */
+.bar.plan {
+  grid-row: 1;
+}
+
 .bar.actual {
+  grid-row: 2;
   background: linear-gradient(135deg, var(--actual), var(--actual-2));
   color: #0f172a;
   box-shadow: 0 5px 12px rgba(249, 115, 22, 0.28);
@@ -626,6 +644,26 @@ body {
   box-shadow: 0 6px 14px rgba(239, 68, 68, 0.25);
 }
 
+.bar.status-colored {
+  background: var(--status-color);
+  box-shadow: 0 6px 14px rgba(15, 23, 42, 0.18);
+}
+
+.bar.progress.status-colored {
+  background: linear-gradient(
+    90deg,
+    var(--status-color) 0%,
+    var(--status-color) calc(var(--progress) * 1%),
+    var(--progress-remaining) calc(var(--progress) * 1%),
+    #f87171 100%
+  );
+}
+
+.bar.blocked {
+  outline: 2px dashed #b91c1c;
+  outline-offset: 1px;
+}
+
 .heading-spacer {
   height: var(--heading-row-height);
   border-bottom: 1px dashed var(--task-border-line);
@@ -730,16 +768,17 @@ const pageTemplate = `<!DOCTYPE html>
 <head>
   <meta charset="UTF-8">
   <meta name="viewport" content="width=device-width, initial-scale=1">
-  <title>Gantt Chart</title>
+  <title>{{.Title}}</title>
   <style>{{.CSS}}</style>
 </head>
 <body{{if .BodyClass}} class="{{.BodyClass}}"{{end}}>
   <div class="page">
-    <h1>Gantt Chart</h1>
+    <h1>{{.Title}}</h1>
     <div class="legend-row">
       <div class="legend">
         <div class="legend-item"><span class="legend-swatch plan"></span><span>予定</span></div>
         {{if .HasActual}}<div class="legend-item"><span class="legend-swatch actual"></span><span>実績</span></div>{{end}}
+        {{range .StatusLegend}}<div class="legend-item"><span class="legend-swatch status" style="--status-color:{{.Color}}"></span><span>{{.Name}}</span></div>{{end}}
       </div>
       {{if .HasCustomColumns}}
       <div class="column-toggles" id="custom-column-toggles">
@@ -749,7 +788,7 @@ const pageTemplate = `<!DOCTYPE html>
         {{end}}
       </div>
       {{end}}
-      {{if .HasNotes}}<button id="toggle-notes" class="toggle-notes" type="button">備考を隠す</button>{{end}}
+      {{if .HasNotes}}<button id="toggle-notes" class="toggle-notes" type="button">{{if .NotesHidden}}備考を表示{{else}}備考を隠す{{end}}</button>{{end}}
     </div>
     {{if .FilterColumns}}
     <div class="filters" id="filters">
@@ -778,11 +817,11 @@ const pageTemplate = `<!DOCTYPE html>
         <div class="name header" data-filter-key="name">Task</div>
         {{range $i, $row := .Rows}}
           {{if $row.Heading}}
-            <div class="heading row-name{{if $row.HeadingMuted}} row-cancelled{{end}}" data-row="{{$i}}" data-heading="true" data-name="{{$row.FilterName}}" data-status="{{$row.FilterStatus}}" data-progress="{{$row.FilterProgress}}" data-notes="{{$row.FilterNotes}}"{{range $ci, $cname := $.CustomColumns}} data-custom-{{$ci}}="{{index $row.CustomValues $ci}}"{{end}}>{{$row.Heading}}</div>
+            <div class="heading row-name{{if $row.HeadingMuted}} row-cancelled{{end}}" data-row="{{$i}}"{{if $row.ID}} data-id="{{$row.ID}}"{{end}} data-heading="true" data-level="{{$row.HeadingLevel}}" data-name="{{$row.FilterName}}" data-status="{{$row.FilterStatus}}" data-semantic="{{$row.FilterSemantic}}" data-progress="{{$row.FilterProgress}}" data-notes="{{$row.FilterNotes}}"{{range $ci, $cname := $.CustomColumns}} data-custom-{{$ci}}="{{index $row.CustomValues $ci}}"{{end}}>{{$row.Heading}}</div>
           {{else if $row.DisplayOnly}}
-            <div class="name row-name" data-row="{{$i}}" data-name="{{$row.FilterName}}" data-status="{{$row.FilterStatus}}" data-progress="{{$row.FilterProgress}}" data-notes="{{$row.FilterNotes}}"{{range $ci, $cname := $.CustomColumns}} data-custom-{{$ci}}="{{index $row.CustomValues $ci}}"{{end}}>{{$row.DisplayOnly}}</div>
+            <div class="name row-name" data-row="{{$i}}"{{if $row.ID}} data-id="{{$row.ID}}"{{end}} data-name="{{$row.FilterName}}" data-status="{{$row.FilterStatus}}" data-semantic="{{$row.FilterSemantic}}" data-progress="{{$row.FilterProgress}}" data-notes="{{$row.FilterNotes}}"{{range $ci, $cname := $.CustomColumns}} data-custom-{{$ci}}="{{index $row.CustomValues $ci}}"{{end}}>{{$row.DisplayOnly}}</div>
           {{else if $row.Task}}
-            <div class="name row-name{{if $row.Task.Cancelled}} row-cancelled{{end}}" data-row="{{$i}}" data-name="{{$row.FilterName}}" data-status="{{$row.FilterStatus}}" data-progress="{{$row.FilterProgress}}" data-notes="{{$row.FilterNotes}}"{{range $ci, $cname := $.CustomColumns}} data-custom-{{$ci}}="{{index $row.CustomValues $ci}}"{{end}}>{{$row.Task.Name}}</div>
+            <div class="name row-name{{if $row.Task.Cancelled}} row-cancelled{{end}}" data-row="{{$i}}"{{if $row.ID}} data-id="{{$row.ID}}"{{end}} data-name="{{$row.FilterName}}" data-status="{{$row.FilterStatus}}" data-semantic="{{$row.FilterSemantic}}" data-progress="{{$row.FilterProgress}}" data-notes="{{$row.FilterNotes}}"{{range $ci, $cname := $.CustomColumns}} data-custom-{{$ci}}="{{index $row.CustomValues $ci}}"{{end}}>{{$row.Task.Name}}</div>
           {{end}}
         {{end}}
       </div>
@@ -855,18 +894,21 @@ const pageTemplate = `<!DOCTYPE html>
         </div>
         <div class="timeline-body-scroll">
           <div class="grid-surface">
-            <div class="today-line"></div>
+            {{if .ShowToday}}<div class="today-line"></div>{{end}}
             <div class="bars">
               {{range $i, $row := .Rows}}
                 {{if $row.Heading}}
-                  <div class="heading-spacer row-bar{{if $row.HeadingMuted}} row-cancelled{{end}}" data-row="{{$i}}"></div>
+                  <div class="heading-spacer row-bar{{if $row.HeadingMuted}} row-cancelled{{end}}" data-row="{{$i}}"{{if $row.ID}} data-id="{{$row.ID}}"{{end}}></div>
                 {{else if $row.DisplayOnly}}
-                  <div class="heading-spacer row-bar" data-row="{{$i}}"></div>
+                  <div class="heading-spacer row-bar" data-row="{{$i}}"{{if $row.ID}} data-id="{{$row.ID}}"{{end}}></div>
               {{else if $row.Task}}
-                <div class="bar-row grid row-bar{{if $row.Task.Cancelled}} row-cancelled{{end}}" data-row="{{$i}}">
-                  <div class="bar plan{{if $row.Task.HasProgress}} progress{{end}}{{if isOneDay $row.Task.Span}} one-day{{end}}" style="grid-column:{{add1 $row.Task.StartIndex}} / span {{$row.Task.Span}};{{if $row.Task.HasProgress}}--progress:{{$row.Task.ProgressPercent}};{{end}}" title="予定: {{formatDate $row.Task.Start}} - {{formatDate $row.Task.End}}{{if $row.Task.HasProgress}} (進捗 {{$row.Task.ProgressText}}){{end}}">予定</div>
-                  {{if $row.Task.Actual}}
-                    <div class="bar actual{{if isOneDay $row.Task.Actual.Span}} one-day{{end}}" style="grid-column:{{add1 $row.Task.Actual.StartIndex}} / span {{$row.Task.Actual.Span}};" title="実績: {{formatDate $row.Task.Actual.Start}} - {{formatDate $row.Task.Actual.End}}">実績</div>
+                <div class="bar-row grid row-bar{{if $row.Task.Cancelled}} row-cancelled{{end}}" data-row="{{$i}}"{{if $row.ID}} data-id="{{$row.ID}}"{{end}}>
+                  {{range $row.Task.Plans}}
+                    <div class="bar plan{{if $row.Task.HasProgress}} progress{{end}}{{if $row.Task.StatusColor}} status-colored{{end}}{{if $row.Task.Blocked}} blocked{{end}}{{if isOneDay .Span}} one-day{{end}}" style="grid-column:{{add1 .StartIndex}} / span {{.Span}};{{if $row.Task.HasProgress}}--progress:{{$row.Task.ProgressPercent}};{{end}}{{if $row.Task.StatusColor}}--status-color:{{$row.Task.StatusColor}};{{end}}" title="予定{{if .Label}} {{.Label}}{{end}}: {{formatDate .Start}} - {{formatDate .End}}{{if $row.Task.HasProgress}} (進捗 {{$row.Task.ProgressText}}{{if $row.Task.ProgressEstimated}}・工数から推定{{end}}){{end}}{{if $row.Task.PlanHours}} 工数: 予定 {{$row.Task.PlanHours}} / 実績 {{$row.Task.ActualHours}}{{end}}">予定{{if $row.Task.PlanHours}} {{$row.Task.PlanHours}}{{end}}</div>
+                  {{end}}
+                  {{$multiple := gt (len $row.Task.Actuals) 1}}
+                  {{range $row.Task.Actuals}}
+                    <div class="bar actual{{if isOneDay .Span}} one-day{{end}}" style="grid-column:{{add1 .StartIndex}} / span {{.Span}};" title="実績: {{formatDate .Start}} - {{formatDate .End}}{{if $multiple}} (計 {{$row.Task.ActualDays}} 日){{end}}{{if $row.Task.ActualHours}} 工数: {{$row.Task.ActualHours}}{{if $row.Task.People}} ({{$row.Task.People}}){{end}}{{end}}">実績{{if $row.Task.ActualHours}} {{$row.Task.ActualHours}}{{end}}</div>
                   {{end}}
                 </div>
               {{end}}
@@ -1087,6 +1129,7 @@ const pageTemplate = `<!DOCTYPE html>
       var getRowValue = function(rowEl, key) {
         if (key === 'name') return rowEl.getAttribute('data-name') || '';
         if (key === 'status') return rowEl.getAttribute('data-status') || '';
+        if (key === 'semantic') return rowEl.getAttribute('data-semantic') || '';
         if (key === 'progress') return rowEl.getAttribute('data-progress') || '';
         if (key === 'notes') return rowEl.getAttribute('data-notes') || '';
         if (key.indexOf('custom-') === 0) {
@@ -1132,14 +1175,16 @@ const pageTemplate = `<!DOCTYPE html>
           matches[idx] = rowMatches(rowEl, states);
         });
 
-        // Keep section headers when any row in the section matches.
-        for (var i = 0; i < rowNames.length; i++) {
+        // Keep section headers when any row in the section (including nested sections) matches.
+        var levelOf = function(el) { return parseInt(el.getAttribute('data-level') || '0', 10); };
+        for (var i = rowNames.length - 1; i >= 0; i--) {
           var rowEl = rowNames[i];
           if (rowEl.getAttribute('data-heading') !== 'true') continue;
           if (matches[i]) continue;
+          var level = levelOf(rowEl);
           var hasVisible = false;
           for (var j = i + 1; j < rowNames.length; j++) {
-            if (rowNames[j].getAttribute('data-heading') === 'true') break;
+            if (rowNames[j].getAttribute('data-heading') === 'true' && levelOf(rowNames[j]) <= level) break;
             if (matches[j]) {
               hasVisible = true;
               break;
@@ -1185,25 +1230,19 @@ const pageTemplate = `<!DOCTYPE html>
         }
       });
 
-      var setGroupHidden = function(headingEl, hidden) {
-        var startId = headingEl.getAttribute('data-row');
-        var startIndex = rowIdToIndex[startId];
-        if (startIndex == null) return;
+      var levelOf = function(el) { return parseInt(el.getAttribute('data-level') || '0', 10); };
 
-        var endIndex = rowNames.length;
-        for (var i = 0; i < headingRows.length; i++) {
-          if (headingRows[i] === headingEl) {
-            if (i + 1 < headingRows.length) {
-              var nextId = headingRows[i + 1].getAttribute('data-row');
-              endIndex = rowIdToIndex[nextId];
-            }
-            break;
-          }
+      // A section ends at the next heading of the same or a shallower level.
+      var groupEnd = function(startIndex) {
+        var level = levelOf(rowNames[startIndex]);
+        for (var i = startIndex + 1; i < rowNames.length; i++) {
+          if (rowNames[i].getAttribute('data-heading') === 'true' && levelOf(rowNames[i]) <= level) return i;
         }
+        return rowNames.length;
+      };
 
-        headingEl.setAttribute('data-group-hidden', hidden ? 'true' : 'false');
-
-        for (var idx = startIndex + 1; idx < endIndex; idx++) {
+      var setRowsHidden = function(from, to, hidden) {
+        for (var idx = from; idx < to; idx++) {
           var rowId = rowNames[idx].getAttribute('data-row');
           var rowEls = document.querySelectorAll('[data-row="' + rowId + '"]');
           rowEls.forEach(function(el) {
@@ -1216,6 +1255,27 @@ const pageTemplate = `<!DOCTYPE html>
         }
       };
 
+      var setGroupHidden = function(headingEl, hidden) {
+        var startId = headingEl.getAttribute('data-row');
+        var startIndex = rowIdToIndex[startId];
+        if (startIndex == null) return;
+
+        var endIndex = groupEnd(startIndex);
+        headingEl.setAttribute('data-group-hidden', hidden ? 'true' : 'false');
+        setRowsHidden(startIndex + 1, endIndex, hidden);
+        if (hidden) return;
+
+        // Nested sections collapsed on their own stay collapsed.
+        for (var i = startIndex + 1; i < endIndex; i++) {
+          var rowEl = rowNames[i];
+          if (rowEl.getAttribute('data-heading') === 'true' && rowEl.getAttribute('data-group-hidden') === 'true') {
+            var innerEnd = groupEnd(i);
+            setRowsHidden(i + 1, innerEnd, true);
+            i = innerEnd - 1;
+          }
+        }
+      };
+
       var toggleGroup = function(headingEl) {
         var isHidden = headingEl.getAttribute('data-group-hidden') === 'true';
         setGroupHidden(headingEl, !isHidden);
@@ -0,0 +1,165 @@
+package renderer
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+// Event selections of BuildICS (Options.ICSEvents).
+const (
+	// ICSAllTasks writes every task over its planned dates.
+	ICSAllTasks = "all"
+	// ICSMilestones writes milestones and tasks with an end date fixed in the input, on
+	// that day.
+	ICSMilestones = "milestones"
+)
+
+// icsLineLimit is the maximum length of a content line in octets (RFC 5545 3.1).
+const icsLineLimit = 75
+
+// BuildICS converts scheduled tasks into an iCalendar file of all-day events. Each
+// event has a UID derived from the task key, so importing the file again updates the
+// events instead of duplicating them, even after the title changes. The description carries the status,
+// progress, dates, dependencies, custom column values and notes. Cancelled tasks are
+// written as cancelled events; headings and display-only rows are left out. Options.Title,
+// Options.CustomColumns and Options.ICSEvents are used.
+func BuildICS(tasks []model.Task, opts Options) (string, error) {
+	return buildICS(tasks, opts, time.Now().UTC())
+}
+
+func buildICS(tasks []model.Task, opts Options, now time.Time) (string, error) {
+	mode := strings.ToLower(opts.ICSEvents)
+	if mode == "" {
+		mode = ICSAllTasks
+	}
+	if mode != ICSAllTasks && mode != ICSMilestones {
+		return "", fmt.Errorf("unknown calendar events %q (use %s or %s)", opts.ICSEvents, ICSAllTasks, ICSMilestones)
+	}
+	title := opts.Title
+	if title == "" {
+		title = defaultTitle
+	}
+
+	var (
+		b        strings.Builder
+		headings []string
+		count    int
+	)
+	line := func(name, value string) {
+		writeICSLine(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//ganttgen//ganttgen//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icsText(title))
+	for _, t := range tasks {
+		if t.IsHeading {
+			headings = append(headings[:min(t.Level, len(headings))], t.Name)
+			continue
+		}
+		if t.DisplayOnly {
+			continue
+		}
+		start, end := t.ComputedStart, t.ComputedEnd
+		if mode == ICSMilestones {
+			if !t.Milestone && t.End == nil {
+				continue
+			}
+			start = end
+		}
+		count++
+		line("BEGIN", "VEVENT")
+		line("UID", icsUID(t.Key()))
+		line("DTSTAMP", now.Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", start.Format("20060102"))
+		line("DTEND;VALUE=DATE", end.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", icsText(t.Name))
+		line("DESCRIPTION", icsText(icsDescription(t, opts.CustomColumns)))
+		if len(headings) > 0 {
+			line("CATEGORIES", icsText(strings.Join(headings, " / ")))
+		}
+		if t.IsCancelled() {
+			line("STATUS", "CANCELLED")
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	if count == 0 {
+		return "", fmt.Errorf("no schedulable tasks to render")
+	}
+	return b.String(), nil
+}
+
+// icsUID returns the event UID of a task: a hash of the task key, so that it stays the
+// same across exports.
+func icsUID(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:10]) + "@ganttgen"
+}
+
+// icsDescription lists the status, progress, dates, dependencies and custom column values
+// of a task, followed by its notes.
+func icsDescription(t model.Task, customColumns []string) string {
+	var lines []string
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, label+": "+value)
+		}
+	}
+	add("状態", t.Status)
+	if t.ProgressPercent != nil {
+		add("進捗", fmt.Sprintf("%d%%", *t.ProgressPercent))
+	}
+	add("予定", icsSpan(t.ComputedStart, t.ComputedEnd))
+	if t.HasActual() {
+		add("実績", icsSpan(*t.ComputedActualStart, *t.ComputedActualEnd))
+	}
+	add("依存", strings.Join(t.DependsOn, ", "))
+	for i, value := range padCustomValues(t.CustomValues, len(customColumns)) {
+		add(customColumns[i], value)
+	}
+	if t.Notes != "" {
+		lines = append(lines, "", t.Notes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func icsSpan(start, end time.Time) string {
+	if start.Equal(end) {
+		return start.Format("2006-01-02")
+	}
+	return start.Format("2006-01-02") + " 〜 " + end.Format("2006-01-02")
+}
+
+// icsText escapes a TEXT value.
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeICSLine writes a content line, folded into lines of at most icsLineLimit octets
+// without splitting a UTF-8 character.
+func writeICSLine(b *strings.Builder, s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := 0
+		for i := range s {
+			if i > limit {
+				break
+			}
+			cut = i
+		}
+		b.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(s + "\r\n")
+}
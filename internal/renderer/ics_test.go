@@ -0,0 +1,98 @@
+package renderer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+func TestBuildICSWritesAllDayEvents(t *testing.T) {
+	progress := 40
+	now := time.Date(2024, time.May, 1, 9, 30, 0, 0, time.UTC)
+	tasks := []model.Task{
+		{Name: "設計", IsHeading: true},
+		{ID: "a", Name: "要件, 確認", Status: "進行中", ProgressPercent: &progress, CustomValues: []string{"佐藤"}, Notes: "メモ; 1行目\n2行目",
+			ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 5)},
+		{Name: "定例", DisplayOnly: true},
+		{ID: "m", Name: "リリース", Milestone: true, DependsOn: []string{"a"}, ComputedStart: day(2024, time.June, 5), ComputedEnd: day(2024, time.June, 5)},
+		{ID: "x", Name: "中止", Status: "中止", End: ptrTime(day(2024, time.June, 7)), ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 7)},
+	}
+	out, err := buildICS(tasks, Options{Title: "計画", CustomColumns: []string{"担当"}}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:計画\r\n",
+		"BEGIN:VEVENT\r\nUID:" + icsUID("a") + "\r\nDTSTAMP:20240501T093000Z\r\nDTSTART;VALUE=DATE:20240603\r\nDTEND;VALUE=DATE:20240606\r\nSUMMARY:要件\\, 確認\r\n",
+		"DESCRIPTION:状態: 進行中\\n進捗: 40%\\n予定: 2024-06-03 〜 2024-06-05\\n担当: 佐藤\\n\\nメモ\\; 1行目\\n2行目\r\n",
+		"CATEGORIES:設計\r\n",
+		"DTSTART;VALUE=DATE:20240605\r\nDTEND;VALUE=DATE:20240606\r\nSUMMARY:リリース\r\n",
+		"STATUS:CANCELLED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 3 || strings.Contains(out, "定例") {
+		t.Fatalf("expected one event per scheduled task:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > icsLineLimit {
+			t.Fatalf("line longer than %d octets: %q", icsLineLimit, line)
+		}
+	}
+
+	again, err := buildICS(tasks, Options{Title: "計画", ICSEvents: ICSMilestones}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(again, "BEGIN:VEVENT") != 2 || strings.Contains(again, "SUMMARY:要件") {
+		t.Fatalf("expected the milestone and the fixed end date only:\n%s", again)
+	}
+	// The deadline is a single day, and keeps the UID of the task.
+	if !strings.Contains(again, "UID:"+icsUID("x")+"\r\nDTSTAMP:20240501T093000Z\r\nDTSTART;VALUE=DATE:20240607\r\nDTEND;VALUE=DATE:20240608\r\n") {
+		t.Fatalf("unexpected deadline event:\n%s", again)
+	}
+	if _, err := buildICS(tasks, Options{ICSEvents: "weekly"}, now); err == nil {
+		t.Fatalf("expected an error for unknown events")
+	}
+	if _, err := buildICS(tasks[:3], Options{ICSEvents: ICSMilestones}, now); err == nil || !strings.Contains(err.Error(), "no schedulable tasks") {
+		t.Fatalf("expected an error when no milestones are left, got %v", err)
+	}
+
+	renamed, err := buildICS(tasks, Options{Title: "新計画"}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	uids := func(ics string) []string {
+		var lines []string
+		for _, line := range strings.Split(ics, "\r\n") {
+			if strings.HasPrefix(line, "UID:") {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+	if got, want := uids(renamed), uids(out); !slices.Equal(got, want) {
+		t.Fatalf("renaming the calendar changed the UIDs: %q, want %q", got, want)
+	}
+}
+
+func TestWriteICSLineFoldsWithoutSplittingCharacters(t *testing.T) {
+	var b strings.Builder
+	line := "SUMMARY:" + strings.Repeat("あ", 40)
+	writeICSLine(&b, line)
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) != 2 || len(lines[0]) > icsLineLimit || len(lines[1]) > icsLineLimit || !strings.HasPrefix(lines[1], " ") {
+		t.Fatalf("unexpected folding: %q", lines)
+	}
+	if got := strings.ReplaceAll(b.String(), "\r\n ", ""); got != line+"\r\n" {
+		t.Fatalf("folded line does not unfold to the original: %q", got)
+	}
+}
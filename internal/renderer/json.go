@@ -0,0 +1,198 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+	"ganttgen/internal/scheduler"
+)
+
+// JSONSchemaVersion is the schema_version of BuildJSON output. It is raised when a field
+// is removed or changes meaning; fields may be added without a new version.
+const JSONSchemaVersion = 1
+
+const jsonDateLayout = "2006-01-02"
+
+type jsonDocument struct {
+	SchemaVersion int        `json:"schema_version"`
+	Title         string     `json:"title"`
+	Today         string     `json:"today"`
+	Start         string     `json:"start"`
+	End           string     `json:"end"`
+	CustomColumns []string   `json:"custom_columns"`
+	Tasks         []jsonTask `json:"tasks"`
+}
+
+type jsonTask struct {
+	Key            string            `json:"key"`
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Section        []string          `json:"section"`
+	Status         string            `json:"status"`
+	Semantic       string            `json:"semantic"`
+	Progress       *int              `json:"progress"`
+	Done           bool              `json:"done"`
+	Cancelled      bool              `json:"cancelled"`
+	Milestone      bool              `json:"milestone"`
+	Start          string            `json:"start"`
+	End            string            `json:"end"`
+	DurationDays   int               `json:"duration_days"`
+	Segments       []jsonInterval    `json:"segments"`
+	ActualStart    *string           `json:"actual_start"`
+	ActualEnd      *string           `json:"actual_end"`
+	ActualSegments []jsonInterval    `json:"actual_segments"`
+	DependsOn      []string          `json:"depends_on"`
+	Custom         map[string]string `json:"custom"`
+	Notes          string            `json:"notes"`
+	FloatDays      *int              `json:"float_days"`
+	Critical       bool              `json:"critical"`
+	SlipDays       *int              `json:"slip_days"`
+	Effort         *jsonEffort       `json:"effort"`
+}
+
+type jsonInterval struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type jsonEffort struct {
+	PlannedHours float64  `json:"planned_hours"`
+	ActualHours  float64  `json:"actual_hours"`
+	People       []string `json:"people"`
+}
+
+// BuildJSON serializes scheduled tasks with their computed dates, actuals, sections,
+// custom column values and the float and slip of each task, in the versioned schema
+// described in the README. Display-only rows are left out. Options.Title and
+// Options.CustomColumns are used.
+func BuildJSON(tasks []model.Task, opts Options) ([]byte, error) {
+	return buildJSON(tasks, opts, calendar.DateOnly(time.Now()))
+}
+
+func buildJSON(tasks []model.Task, opts Options, today time.Time) ([]byte, error) {
+	floats := scheduler.Float(tasks)
+	doc := jsonDocument{
+		SchemaVersion: JSONSchemaVersion,
+		Title:         opts.Title,
+		Today:         today.Format(jsonDateLayout),
+		CustomColumns: append([]string{}, opts.CustomColumns...),
+		Tasks:         []jsonTask{},
+	}
+	if doc.Title == "" {
+		doc.Title = defaultTitle
+	}
+	var (
+		headings   []string
+		start, end time.Time
+	)
+	for _, t := range tasks {
+		if t.IsHeading {
+			headings = append(headings[:min(t.Level, len(headings))], t.Name)
+			continue
+		}
+		if t.DisplayOnly {
+			continue
+		}
+		if len(doc.Tasks) == 0 || t.ComputedStart.Before(start) {
+			start = t.ComputedStart
+		}
+		if len(doc.Tasks) == 0 || t.ComputedEnd.After(end) {
+			end = t.ComputedEnd
+		}
+		doc.Tasks = append(doc.Tasks, newJSONTask(t, append([]string{}, headings...), opts.CustomColumns, floats, today))
+	}
+	if len(doc.Tasks) == 0 {
+		return nil, fmt.Errorf("no schedulable tasks to render")
+	}
+	doc.Start = start.Format(jsonDateLayout)
+	doc.End = end.Format(jsonDateLayout)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newJSONTask(t model.Task, section, customColumns []string, floats map[string]int, today time.Time) jsonTask {
+	done := t.IsCompleted() || (t.ProgressPercent != nil && *t.ProgressPercent >= 100)
+	jt := jsonTask{
+		Key:            t.Key(),
+		ID:             t.ID,
+		Name:           t.Name,
+		Section:        section,
+		Status:         t.Status,
+		Semantic:       string(t.Semantic()),
+		Progress:       t.ProgressPercent,
+		Done:           done,
+		Cancelled:      t.IsCancelled(),
+		Milestone:      t.Milestone,
+		Start:          t.ComputedStart.Format(jsonDateLayout),
+		End:            t.ComputedEnd.Format(jsonDateLayout),
+		Segments:       jsonIntervals(t.PlanSegments()),
+		ActualSegments: jsonIntervals(t.ActualIntervals()),
+		DependsOn:      append([]string{}, t.DependsOn...),
+		Custom:         make(map[string]string, len(customColumns)),
+		Notes:          t.Notes,
+	}
+	if !t.Milestone {
+		for _, seg := range t.PlanSegments() {
+			jt.DurationDays += calendar.CountWorkdays(seg.Start, seg.End)
+		}
+	}
+	if t.ComputedActualStart != nil && t.ComputedActualEnd != nil {
+		actualStart := t.ComputedActualStart.Format(jsonDateLayout)
+		actualEnd := t.ComputedActualEnd.Format(jsonDateLayout)
+		jt.ActualStart, jt.ActualEnd = &actualStart, &actualEnd
+	}
+	for i, value := range padCustomValues(t.CustomValues, len(customColumns)) {
+		jt.Custom[customColumns[i]] = value
+	}
+	if float, ok := floats[t.Key()]; ok {
+		jt.FloatDays = &float
+		jt.Critical = float <= 0
+	}
+	if !jt.Cancelled {
+		jt.SlipDays = slipDays(t, done, today)
+	}
+	if t.Effort != nil {
+		jt.Effort = &jsonEffort{
+			PlannedHours: t.Effort.PlannedHours,
+			ActualHours:  t.Effort.ActualHours,
+			People:       append([]string{}, t.Effort.People...),
+		}
+	}
+	return jt
+}
+
+// slipDays returns how many workdays a task finished, or is running, behind its planned
+// end: for a done task the actual end against the plan (negative when early), for an
+// unfinished task the workdays since a planned end that has passed. A done task without
+// an actual end has no slip.
+func slipDays(t model.Task, done bool, today time.Time) *int {
+	var slip int
+	switch {
+	case done && t.ComputedActualEnd == nil:
+		return nil
+	case done:
+		slip = calendar.WorkdaysBetween(t.ComputedEnd, *t.ComputedActualEnd)
+	case today.After(t.ComputedEnd):
+		slip = calendar.WorkdaysBetween(t.ComputedEnd, today)
+	}
+	return &slip
+}
+
+func jsonIntervals(intervals []model.Interval) []jsonInterval {
+	out := make([]jsonInterval, 0, len(intervals))
+	for _, iv := range intervals {
+		out = append(out, jsonInterval{Start: iv.Start.Format(jsonDateLayout), End: iv.End.Format(jsonDateLayout)})
+	}
+	return out
+}
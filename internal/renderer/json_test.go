@@ -0,0 +1,81 @@
+package renderer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+func TestBuildJSONWritesScheduleAndMetrics(t *testing.T) {
+	progress := 50
+	actualEnd := day(2024, time.June, 6)
+	tasks := []model.Task{
+		{Name: "設計", IsHeading: true},
+		{Name: "詳細", IsHeading: true, Level: 1},
+		{ID: "a", Name: "要件 <1>", Status: "完了", CustomValues: []string{"佐藤"}, ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 4),
+			ComputedActualStart: ptrTime(day(2024, time.June, 3)), ComputedActualEnd: &actualEnd},
+		{ID: "b", Name: "画面", ProgressPercent: &progress, ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)},
+		{Name: "定例", DisplayOnly: true},
+		{Name: "実装", IsHeading: true},
+		{ID: "c", Name: "実装", DependsOn: []string{"a", "b"}, ComputedStart: day(2024, time.June, 5), ComputedEnd: day(2024, time.June, 11)},
+		{ID: "x", Name: "中止", Status: "中止", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 20)},
+	}
+	data, err := buildJSON(tasks, Options{CustomColumns: []string{"担当", "チーム"}}, day(2024, time.June, 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"name": "要件 <1>"`) {
+		t.Fatalf("expected unescaped names:\n%s", data)
+	}
+	var doc struct {
+		SchemaVersion int    `json:"schema_version"`
+		Title         string `json:"title"`
+		Today         string `json:"today"`
+		Start         string `json:"start"`
+		End           string `json:"end"`
+		Tasks         []struct {
+			Key            string            `json:"key"`
+			Section        []string          `json:"section"`
+			Done           bool              `json:"done"`
+			Cancelled      bool              `json:"cancelled"`
+			Progress       *int              `json:"progress"`
+			DurationDays   int               `json:"duration_days"`
+			ActualEnd      *string           `json:"actual_end"`
+			ActualSegments []jsonInterval    `json:"actual_segments"`
+			DependsOn      []string          `json:"depends_on"`
+			Custom         map[string]string `json:"custom"`
+			FloatDays      *int              `json:"float_days"`
+			Critical       bool              `json:"critical"`
+			SlipDays       *int              `json:"slip_days"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if doc.SchemaVersion != JSONSchemaVersion || doc.Title != defaultTitle || doc.Today != "2024-06-10" || doc.Start != "2024-06-03" || doc.End != "2024-06-20" {
+		t.Fatalf("unexpected document header: %+v", doc)
+	}
+	if len(doc.Tasks) != 4 {
+		t.Fatalf("expected 4 tasks without headings and display-only rows, got %+v", doc.Tasks)
+	}
+	a, b, c, x := doc.Tasks[0], doc.Tasks[1], doc.Tasks[2], doc.Tasks[3]
+	if strings.Join(a.Section, "/") != "設計/詳細" || strings.Join(c.Section, "/") != "実装" {
+		t.Fatalf("unexpected sections: %v, %v", a.Section, c.Section)
+	}
+	if !a.Done || a.ActualEnd == nil || *a.ActualEnd != "2024-06-06" || len(a.ActualSegments) != 1 || a.Custom["担当"] != "佐藤" || a.Custom["チーム"] != "" {
+		t.Fatalf("unexpected task a: %+v", a)
+	}
+	// a finished two workdays late; b is still open a week after its planned end.
+	if *a.SlipDays != 2 || *b.SlipDays != 5 || *c.SlipDays != 0 || x.SlipDays != nil {
+		t.Fatalf("unexpected slips: %v %v %v %v", *a.SlipDays, *b.SlipDays, *c.SlipDays, x.SlipDays)
+	}
+	if *a.FloatDays != 0 || *b.FloatDays != 1 || !c.Critical || b.Critical || x.FloatDays != nil || !x.Cancelled {
+		t.Fatalf("unexpected floats: a=%v b=%v c=%+v x=%+v", *a.FloatDays, *b.FloatDays, c, x)
+	}
+	if c.DurationDays != 5 || strings.Join(c.DependsOn, ",") != "a,b" || b.Progress == nil || *b.Progress != 50 {
+		t.Fatalf("unexpected task c: %+v", c)
+	}
+}
@@ -0,0 +1,207 @@
+package renderer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// aliasPattern matches task IDs usable as they are for references in text diagrams.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// BuildMermaid converts scheduled tasks into a Mermaid gantt diagram. Heading rows become
+// sections, dependencies become "after" starts where Mermaid derives the same date, and
+// non-working days from the calendar become excludes. Cancelled and display-only rows are
+// left out. Only Options.Title is used.
+func BuildMermaid(tasks []model.Task, opts Options) (string, error) {
+	today := calendar.DateOnly(time.Now())
+	ids := taskAliases(tasks)
+	var (
+		b        strings.Builder
+		headings []string
+		start    time.Time
+		end      time.Time
+		emitted  = make(map[string]time.Time) // task key -> exclusive end in the diagram
+		count    int
+	)
+	var body strings.Builder
+	for _, t := range tasks {
+		if t.IsHeading {
+			// Mermaid sections are flat: nested headings are joined with their parents.
+			headings = append(headings[:min(t.Level, len(headings))], t.Name)
+			fmt.Fprintf(&body, "    section %s\n", mermaidText(strings.Join(headings, " / ")))
+			continue
+		}
+		if t.DisplayOnly || t.IsCancelled() {
+			continue
+		}
+		segments := t.PlanSegments()
+		for i, seg := range segments {
+			if count == 0 || seg.Start.Before(start) {
+				start = seg.Start
+			}
+			if count == 0 || seg.End.After(end) {
+				end = seg.End
+			}
+			count++
+			name, id := t.Name, ids[t.Key()]
+			if len(segments) > 1 {
+				name = fmt.Sprintf("%s (%d/%d)", t.Name, i+1, len(segments))
+				if i < len(segments)-1 {
+					// The last segment keeps the task id so that "after" waits for all of it.
+					id = fmt.Sprintf("%s_%d", id, i+1)
+				}
+			}
+			meta := mermaidTags(t, today)
+			meta = append(meta, id)
+			from := seg.Start.Format("2006-01-02")
+			if i == 0 {
+				if after := mermaidAfter(t, seg.Start, emitted, ids); after != "" {
+					from = after
+				}
+			}
+			until := seg.End.AddDate(0, 0, 1).Format("2006-01-02")
+			if t.Milestone {
+				until = "0d"
+			}
+			meta = append(meta, from, until)
+			fmt.Fprintf(&body, "    %s :%s\n", mermaidText(name), strings.Join(meta, ", "))
+		}
+		emitted[t.Key()] = segments[len(segments)-1].End.AddDate(0, 0, 1)
+		if t.Milestone {
+			// A Mermaid milestone ends where it starts.
+			emitted[t.Key()] = t.ComputedStart
+		}
+	}
+	if count == 0 {
+		return "", fmt.Errorf("no schedulable tasks to render")
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = defaultTitle
+	}
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "    title %s\n", mermaidText(title))
+	b.WriteString("    dateFormat YYYY-MM-DD\n")
+	b.WriteString("    axisFormat %m/%d\n")
+	var excludes []string
+	for _, d := range calendar.Weekends() {
+		excludes = append(excludes, strings.ToLower(d.String()))
+	}
+	closed, open := calendar.Exceptions(start, end)
+	for _, d := range closed {
+		excludes = append(excludes, d.Format("2006-01-02"))
+	}
+	if len(excludes) > 0 {
+		fmt.Fprintf(&b, "    excludes %s\n", strings.Join(excludes, ", "))
+	}
+	if len(open) > 0 {
+		var includes []string
+		for _, d := range open {
+			includes = append(includes, d.Format("2006-01-02"))
+		}
+		fmt.Fprintf(&b, "    includes %s\n", strings.Join(includes, ", "))
+	}
+	b.WriteString(body.String())
+	return b.String(), nil
+}
+
+// mermaidTags maps status and progress to Mermaid tags: done, active and crit for blocked
+// or overdue tasks, plus milestone.
+func mermaidTags(t model.Task, today time.Time) []string {
+	var tags []string
+	if t.Milestone {
+		tags = append(tags, "milestone")
+	}
+	done := t.IsCompleted() || (t.ProgressPercent != nil && *t.ProgressPercent >= 100)
+	if done {
+		return append(tags, "done")
+	}
+	if t.Semantic() == model.SemanticBlocked || t.ComputedEnd.Before(today) {
+		tags = append(tags, "crit")
+	}
+	started := t.ComputedActualStart != nil || (t.ProgressPercent != nil && *t.ProgressPercent > 0)
+	if t.Semantic() == model.SemanticInProgress || started {
+		tags = append(tags, "active")
+	}
+	return tags
+}
+
+// mermaidAfter returns "after a b" when Mermaid would start the task on the same day:
+// the dependencies are in the diagram and the latest of them ends the day before start.
+func mermaidAfter(t model.Task, start time.Time, emitted map[string]time.Time, ids map[string]string) string {
+	if len(t.DependsOn) == 0 {
+		return ""
+	}
+	var latest time.Time
+	refs := make([]string, 0, len(t.DependsOn))
+	for _, dep := range t.DependsOn {
+		depEnd, ok := emitted[dep]
+		if !ok {
+			return ""
+		}
+		if depEnd.After(latest) {
+			latest = depEnd
+		}
+		refs = append(refs, ids[dep])
+	}
+	if !latest.Equal(calendar.DateOnly(start)) {
+		return ""
+	}
+	return "after " + strings.Join(refs, " ")
+}
+
+// taskAliases assigns each task the id used to reference it in Mermaid and PlantUML: its
+// own ID when usable and unique, t1, t2... otherwise. Generated ids skip the numbers taken
+// by task IDs.
+func taskAliases(tasks []model.Task) map[string]string {
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	var unnamed []int
+	for i, t := range tasks {
+		if t.IsHeading || t.DisplayOnly {
+			continue
+		}
+		if t.Namespace != "" || !aliasPattern.MatchString(t.ID) || used[t.ID] {
+			unnamed = append(unnamed, i)
+			continue
+		}
+		used[t.ID] = true
+		ids[t.Key()] = t.ID
+	}
+	for _, i := range unnamed {
+		n := i + 1
+		id := fmt.Sprintf("t%d", n)
+		for used[id] {
+			n++
+			id = fmt.Sprintf("t%d", n)
+		}
+		used[id] = true
+		ids[tasks[i].Key()] = id
+	}
+	return ids
+}
+
+// mermaidText escapes text for a task or section name: ':', '#' and ';' would end the
+// name, so they become Mermaid entity codes.
+func mermaidText(s string) string {
+	var b strings.Builder
+	for _, r := range strings.Join(strings.Fields(s), " ") {
+		switch r {
+		case '#':
+			b.WriteString("#35;")
+		case ':':
+			b.WriteString("#58;")
+		case ';':
+			b.WriteString("#59;")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+func TestBuildMermaidWritesSectionsAndDependencies(t *testing.T) {
+	calendar.SetHolidays([]time.Time{day(2024, time.June, 12)})
+	t.Cleanup(func() { calendar.SetHolidays(nil) })
+	done := 100
+	tasks := []model.Task{
+		{Name: "設計", IsHeading: true},
+		{ID: "a", Name: "要件: 確認 #1", ProgressPercent: &done, ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 4)},
+		{Name: "詳細", IsHeading: true, Level: 1},
+		{ID: "b", Name: "設計書", Status: "保留", DependsOn: []string{"a"}, ComputedStart: day(2024, time.June, 5), ComputedEnd: day(2024, time.June, 7)},
+		{ID: "c", Name: "レビュー", DependsOn: []string{"b"}, ComputedStart: day(2024, time.June, 10), ComputedEnd: day(2024, time.June, 11)},
+		{ID: "x", Name: "中止タスク", Status: "中止", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)},
+		{ID: "1.2", Name: "実装", ComputedStart: day(2024, time.June, 13), ComputedEnd: day(2024, time.June, 14)},
+	}
+	out, err := BuildMermaid(tasks, Options{Title: "計画"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"gantt\n    title 計画\n    dateFormat YYYY-MM-DD\n",
+		"    excludes sunday, saturday, 2024-06-12\n",
+		"    section 設計\n",
+		"    section 設計 / 詳細\n",
+		"    要件#58; 確認 #35;1 :done, a, 2024-06-03, 2024-06-05\n",
+		"    設計書 :crit, b, after a, 2024-06-08\n",
+		// The weekend lies between b and c, so Mermaid would start c too early with "after".
+		"    レビュー :crit, c, 2024-06-10, 2024-06-12\n",
+		"    実装 :crit, t7, 2024-06-13, 2024-06-15\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "中止タスク") {
+		t.Fatalf("cancelled task should be omitted:\n%s", out)
+	}
+}
+
+func TestBuildMermaidKeepsGeneratedIDsApartFromTaskIDs(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "t2", Name: "A", ComputedStart: day(2099, time.June, 1), ComputedEnd: day(2099, time.June, 2)},
+		{Name: "B", DependsOn: []string{"t2"}, ComputedStart: day(2099, time.June, 3), ComputedEnd: day(2099, time.June, 3)},
+		{ID: "t3", Name: "C", DependsOn: []string{"B"}, ComputedStart: day(2099, time.June, 4), ComputedEnd: day(2099, time.June, 4)},
+	}
+	out, err := BuildMermaid(tasks, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"    A :t2, 2099-06-01, 2099-06-03\n",
+		"    B :t4, after t2, 2099-06-04\n",
+		"    C :t3, after t4, 2099-06-05\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestBuildMermaidSplitsPausedTasksAndMarksMilestones(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "a", Name: "A", ComputedStart: day(2099, time.June, 1), ComputedEnd: day(2099, time.June, 10), ComputedSegments: []model.Interval{
+			{Start: day(2099, time.June, 1), End: day(2099, time.June, 3)},
+			{Start: day(2099, time.June, 8), End: day(2099, time.June, 10)},
+		}},
+		{ID: "b", Name: "B", DependsOn: []string{"a"}, ComputedStart: day(2099, time.June, 11), ComputedEnd: day(2099, time.June, 11)},
+		{ID: "m", Name: "M", Milestone: true, DependsOn: []string{"b"}, ComputedStart: day(2099, time.June, 11), ComputedEnd: day(2099, time.June, 11)},
+	}
+	out, err := BuildMermaid(tasks, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"    A (1/2) :a_1, 2099-06-01, 2099-06-04\n",
+		"    A (2/2) :a, 2099-06-08, 2099-06-11\n",
+		"    B :b, after a, 2099-06-12\n",
+		// Mermaid would put the milestone after b, on the next day.
+		"    M :milestone, m, 2099-06-11, 0d\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
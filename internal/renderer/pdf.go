@@ -0,0 +1,540 @@
+package renderer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/image/math/fixed"
+
+	"ganttgen/internal/model"
+)
+
+// pageSizes lists the supported PDF page sizes in points, landscape.
+var pageSizes = map[string][2]float64{
+	"a3": {1190.55, 841.89},
+	"a4": {841.89, 595.28},
+}
+
+// DefaultPageSize is the PDF page size used when Options.PageSize is empty.
+const DefaultPageSize = "a3"
+
+const (
+	pdfScale     = 0.75 // chart pixels to points (96 dpi to 72 dpi)
+	pdfMargin    = 28   // points, about 10 mm
+	pdfFooter    = 16   // points reserved above the bottom margin for the footer
+	pdfGlyphsMax = 256  // glyphs per Type3 font (single-byte codes)
+)
+
+// BuildPDF renders the chart as a printable PDF with landscape pages (A3 by default,
+// see Options.PageSize). Wide timelines are split into date ranges and long plans into
+// row ranges; every page repeats the title, date header and side columns and carries a
+// footer with the page number and generation date. Text uses the bundled bitmap font,
+// embedded as Type3 fonts so that Japanese prints without installed fonts.
+func BuildPDF(tasks []model.Task, opts Options) ([]byte, error) {
+	sizeName := strings.ToLower(opts.PageSize)
+	if sizeName == "" {
+		sizeName = DefaultPageSize
+	}
+	size, ok := pageSizes[sizeName]
+	if !ok {
+		return nil, fmt.Errorf("unknown page size %q (use a3 or a4)", opts.PageSize)
+	}
+	ctx, err := buildContext(tasks, opts)
+	if err != nil {
+		return nil, err
+	}
+	st, err := newChartStyle(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pageW, pageH := size[0], size[1]
+	areaW := (pageW - 2*pdfMargin) / pdfScale
+	areaH := (pageH - 2*pdfMargin - pdfFooter) / pdfScale
+	daysPerPage := int((areaW - 2*chartPadding - st.sideWidth()) / st.cell)
+	rowsPerPage := int((areaH - chartHeaderHeight - chartPadding) / rowHeight(ctx))
+	if daysPerPage < 1 {
+		return nil, fmt.Errorf("side columns are too wide for a %s page; choose fewer side columns", strings.ToUpper(sizeName))
+	}
+	if rowsPerPage < 1 {
+		return nil, fmt.Errorf("a %s page is too small for one row", strings.ToUpper(sizeName))
+	}
+
+	dayChunks := chunkRange(len(ctx.Days), daysPerPage, nil)
+	rowChunks := chunkRange(len(ctx.Rows), rowsPerPage, func(end int) bool {
+		// Do not leave a section heading alone at the bottom of a page.
+		return ctx.Rows[end-1].Heading != ""
+	})
+	if len(ctx.Rows) == 0 {
+		rowChunks = [][2]int{{0, 0}}
+	}
+
+	doc := newPDFDocument()
+	generated := time.Now().Format("2006-01-02 15:04")
+	total := len(dayChunks) * len(rowChunks)
+	for _, days := range dayChunks {
+		for _, rows := range rowChunks {
+			page := ctx.slice(days[0], days[1], rows[0], rows[1])
+			c := st.layout(page)
+			footerY := areaH + pdfFooter/pdfScale - 4
+			footer := mark{Kind: markText, Y: footerY, FontSize: chartFontSize - 2, Fill: chartMutedText}
+			left, center, right := footer, footer, footer
+			left.X, left.Anchor = chartPadding, "start"
+			left.Text = fmt.Sprintf("%s  %s - %s", ctx.Title, formatDate(page.Days[0]), formatDate(page.Days[len(page.Days)-1]))
+			center.X, center.Anchor = areaW/2, "middle"
+			center.Text = fmt.Sprintf("%d / %d", doc.pageCount()+1, total)
+			right.X, right.Anchor = areaW-chartPadding, "end"
+			right.Text = "生成日 " + generated
+			c.Marks = append(c.Marks, left, center, right)
+			if err := doc.addPage(pageW, pageH, c.Marks); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc.bytes(ctx.Title)
+}
+
+// chunkRange splits [0, n) into ranges of at most size items. keepWithNext, when set,
+// reports whether the item before end should move to the next range instead.
+func chunkRange(n, size int, keepWithNext func(end int) bool) [][2]int {
+	var chunks [][2]int
+	for start := 0; start < n; {
+		end := min(start+size, n)
+		if keepWithNext != nil && end < n {
+			for end-1 > start && keepWithNext(end) {
+				end--
+			}
+		}
+		chunks = append(chunks, [2]int{start, end})
+		start = end
+	}
+	return chunks
+}
+
+// slice returns the context restricted to days [d0, d1) and rows [r0, r1), with the
+// bars moved and clipped to the new first day.
+func (ctx renderContext) slice(d0, d1, r0, r1 int) renderContext {
+	page := ctx
+	page.Days = ctx.Days[d0:d1]
+	page.DayCount = len(page.Days)
+	page.TodayIndex = ctx.TodayIndex - d0
+	page.ShowToday = ctx.ShowToday && ctx.TodayIndex >= d0 && ctx.TodayIndex < d1
+	page.Rows = make([]renderRow, 0, r1-r0)
+	clip := func(bars []renderBar) []renderBar {
+		var res []renderBar
+		for _, b := range bars {
+			from, to := max(b.StartIndex, d0), min(b.StartIndex+b.Span, d1)
+			if from >= to {
+				continue
+			}
+			b.StartIndex, b.Span = from-d0, to-from
+			res = append(res, b)
+		}
+		return res
+	}
+	for _, row := range ctx.Rows[r0:r1] {
+		if row.Task != nil {
+			t := *row.Task
+			t.Plans = clip(t.Plans)
+			t.Actuals = clip(t.Actuals)
+			row.Task = &t
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	return page
+}
+
+// pdfDocument collects pages and the glyphs they use, then writes the PDF file.
+type pdfDocument struct {
+	pages  []pdfPage
+	fonts  *pdfFonts
+	states map[uint8]string // fill alpha -> ExtGState name
+}
+
+type pdfPage struct {
+	width, height float64
+	content       []byte
+}
+
+func newPDFDocument() *pdfDocument {
+	return &pdfDocument{fonts: newPDFFonts(), states: make(map[uint8]string)}
+}
+
+func (d *pdfDocument) pageCount() int {
+	return len(d.pages)
+}
+
+// addPage converts chart marks into a page content stream. Chart coordinates start at
+// the top-left margin and grow downwards, so the page is flipped once here.
+func (d *pdfDocument) addPage(width, height float64, marks []mark) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s 0 0 %s %s %s cm\n", pdfNum(pdfScale), pdfNum(-pdfScale), pdfNum(pdfMargin), pdfNum(height-pdfMargin))
+	for _, m := range marks {
+		if err := d.writeMark(&b, m); err != nil {
+			return err
+		}
+	}
+	d.pages = append(d.pages, pdfPage{width: width, height: height, content: b.Bytes()})
+	return nil
+}
+
+func (d *pdfDocument) writeMark(b *bytes.Buffer, m mark) error {
+	switch m.Kind {
+	case markRect:
+		if m.Fill != "" {
+			c, err := parseColor(m.Fill, m.Opacity)
+			if err != nil {
+				return err
+			}
+			b.WriteString("q\n")
+			d.setColor(b, c, "rg")
+			roundRectPath(b, m.X, m.Y, m.W, m.H, min(m.Radius, m.W/2, m.H/2))
+			b.WriteString("f\nQ\n")
+		}
+		if m.Stroke != "" {
+			c, err := parseColor(m.Stroke, m.Opacity)
+			if err != nil {
+				return err
+			}
+			b.WriteString("q\n")
+			d.setStroke(b, c, m)
+			roundRectPath(b, m.X, m.Y, m.W, m.H, min(m.Radius, m.W/2, m.H/2))
+			b.WriteString("S\nQ\n")
+		}
+	case markLine:
+		c, err := parseColor(m.Stroke, m.Opacity)
+		if err != nil {
+			return err
+		}
+		b.WriteString("q\n")
+		d.setStroke(b, c, m)
+		fmt.Fprintf(b, "%s %s m %s %s l S\nQ\n", pdfNum(m.X), pdfNum(m.Y), pdfNum(m.X+m.W), pdfNum(m.Y+m.H))
+	case markText:
+		c, err := parseColor(m.Fill, m.Opacity)
+		if err != nil {
+			return err
+		}
+		d.writeText(b, m, c)
+	}
+	return nil
+}
+
+func (d *pdfDocument) setColor(b *bytes.Buffer, c color.Color, op string) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A < 0xff {
+		name, ok := d.states[n.A]
+		if !ok {
+			name = fmt.Sprintf("GS%d", len(d.states)+1)
+			d.states[n.A] = name
+		}
+		fmt.Fprintf(b, "/%s gs\n", name)
+	}
+	fmt.Fprintf(b, "%s %s %s %s\n", pdfNum(float64(n.R)/255), pdfNum(float64(n.G)/255), pdfNum(float64(n.B)/255), op)
+}
+
+func (d *pdfDocument) setStroke(b *bytes.Buffer, c color.Color, m mark) {
+	d.setColor(b, c, "RG")
+	fmt.Fprintf(b, "%s w\n", pdfNum(max(m.StrokeWidth, 1)))
+	if m.Dashed {
+		b.WriteString("[4 3] 0 d\n")
+	}
+}
+
+// writeText draws a label with the embedded bitmap font. Glyph space is one unit per
+// font pixel and 12 units per em, so the font size is the chart font size.
+func (d *pdfDocument) writeText(b *bytes.Buffer, m mark, c color.Color) {
+	if m.Text == "" {
+		return
+	}
+	k := m.FontSize / pngFaceSize
+	width := 0.0
+	for _, r := range m.Text {
+		width += d.fonts.glyph(r).advance
+	}
+	width *= k
+	x := m.X
+	switch m.Anchor {
+	case "middle":
+		x -= width / 2
+	case "end":
+		x -= width
+	}
+	b.WriteString("q\n")
+	d.setColor(b, c, "rg")
+	passes := []float64{0}
+	if m.Bold {
+		passes = append(passes, 0.6)
+	}
+	for _, dx := range passes {
+		fmt.Fprintf(b, "BT\n1 0 0 -1 %s %s Tm\n", pdfNum(x+dx), pdfNum(m.Y))
+		font := -1
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				fmt.Fprintf(b, "<%s> Tj\n", run.String())
+				run.Reset()
+			}
+		}
+		for _, r := range m.Text {
+			g := d.fonts.glyph(r)
+			if g.font != font {
+				flush()
+				font = g.font
+				fmt.Fprintf(b, "/T%d %s Tf\n", font+1, pdfNum(m.FontSize))
+			}
+			fmt.Fprintf(&run, "%02X", g.code)
+		}
+		flush()
+		b.WriteString("ET\n")
+	}
+	b.WriteString("Q\n")
+}
+
+// roundRectPath appends a rectangle path with rounded corners of radius r.
+func roundRectPath(b *bytes.Buffer, x, y, w, h, r float64) {
+	if r <= 0 {
+		fmt.Fprintf(b, "%s %s %s %s re\n", pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h))
+		return
+	}
+	const k = 0.5523 // control point distance for a quarter circle
+	x1, y1 := x+w, y+h
+	fmt.Fprintf(b, "%s %s m\n", pdfNum(x+r), pdfNum(y))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x1-r), pdfNum(y))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(x1-r+k*r), pdfNum(y), pdfNum(x1), pdfNum(y+r-k*r), pdfNum(x1), pdfNum(y+r))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x1), pdfNum(y1-r))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(x1), pdfNum(y1-r+k*r), pdfNum(x1-r+k*r), pdfNum(y1), pdfNum(x1-r), pdfNum(y1))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x+r), pdfNum(y1))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(x+r-k*r), pdfNum(y1), pdfNum(x), pdfNum(y1-r+k*r), pdfNum(x), pdfNum(y1-r))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x), pdfNum(y+r))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\nh\n", pdfNum(x), pdfNum(y+r-k*r), pdfNum(x+r-k*r), pdfNum(y), pdfNum(x+r), pdfNum(y))
+}
+
+// pdfGlyph is a character of the embedded font: its Type3 font, code and bitmap.
+type pdfGlyph struct {
+	r       rune
+	font    int
+	code    int
+	advance float64 // font pixels
+	bounds  image.Rectangle
+	bits    []bool // bounds.Dx() * bounds.Dy(), row-major from the top
+}
+
+// pdfFonts assigns the glyphs used in the document to Type3 fonts of up to 256 codes.
+type pdfFonts struct {
+	byRune map[rune]*pdfGlyph
+	glyphs []*pdfGlyph
+}
+
+func newPDFFonts() *pdfFonts {
+	return &pdfFonts{byRune: make(map[rune]*pdfGlyph)}
+}
+
+func (f *pdfFonts) glyph(r rune) *pdfGlyph {
+	if g, ok := f.byRune[r]; ok {
+		return g
+	}
+	n := len(f.glyphs)
+	g := &pdfGlyph{r: r, font: n / pdfGlyphsMax, code: n % pdfGlyphsMax}
+	dr, mask, maskp, advance, ok := pngFace.Glyph(fixed.Point26_6{}, r)
+	if !ok {
+		// Missing glyphs keep their place as blanks.
+		adv, _ := pngFace.GlyphAdvance(' ')
+		advance = adv
+	}
+	g.advance = float64(advance) / 64
+	if ok && !dr.Empty() {
+		g.bounds = dr
+		g.bits = make([]bool, dr.Dx()*dr.Dy())
+		for y := 0; y < dr.Dy(); y++ {
+			for x := 0; x < dr.Dx(); x++ {
+				_, _, _, a := mask.At(maskp.X+x, maskp.Y+y).RGBA()
+				g.bits[y*dr.Dx()+x] = a >= 0x8000
+			}
+		}
+	}
+	f.byRune[r] = g
+	f.glyphs = append(f.glyphs, g)
+	return g
+}
+
+// charProc is the glyph procedure: the glyph bitmap as an image mask painted with the
+// current fill color. Glyph space is y-up with the baseline at 0.
+func (g *pdfGlyph) charProc() []byte {
+	var b bytes.Buffer
+	w, h := g.bounds.Dx(), g.bounds.Dy()
+	fmt.Fprintf(&b, "%s 0 %d %d %d %d d1\n", pdfNum(g.advance), g.bounds.Min.X, -g.bounds.Max.Y, g.bounds.Max.X, -g.bounds.Min.Y)
+	if w == 0 || h == 0 {
+		return b.Bytes()
+	}
+	fmt.Fprintf(&b, "q %d 0 0 %d %d %d cm\nBI /W %d /H %d /IM true /BPC 1 /D [1 0] /F /AHx ID\n", w, h, g.bounds.Min.X, -g.bounds.Max.Y, w, h)
+	for y := 0; y < h; y++ {
+		var row byte
+		for x := 0; x < w; x++ {
+			if g.bits[y*w+x] {
+				row |= 0x80 >> (x % 8)
+			}
+			if x%8 == 7 || x == w-1 {
+				fmt.Fprintf(&b, "%02X", row)
+				row = 0
+			}
+		}
+	}
+	b.WriteString(">\nEI Q\n")
+	return b.Bytes()
+}
+
+// bytes writes the PDF file.
+func (d *pdfDocument) bytes(title string) ([]byte, error) {
+	w := &pdfWriter{}
+	catalog := w.reserve()
+	pages := w.reserve()
+
+	// Type3 fonts.
+	fontCount := (len(d.fonts.glyphs) + pdfGlyphsMax - 1) / pdfGlyphsMax
+	var fontRefs []int
+	for fi := 0; fi < fontCount; fi++ {
+		glyphs := d.fonts.glyphs[fi*pdfGlyphsMax : min((fi+1)*pdfGlyphsMax, len(d.fonts.glyphs))]
+		var procs, diffs, widths, cmap strings.Builder
+		for _, g := range glyphs {
+			ref := w.stream(nil, g.charProc())
+			fmt.Fprintf(&procs, "/g%d %d 0 R ", g.code, ref)
+			fmt.Fprintf(&diffs, "/g%d ", g.code)
+			fmt.Fprintf(&widths, "%s ", pdfNum(g.advance))
+			fmt.Fprintf(&cmap, "<%02X> <%s>\n", g.code, utf16Hex(string(g.r)))
+		}
+		toUnicode := w.stream(nil, []byte("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n"+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n"+
+			"1 begincodespacerange\n<00> <FF>\nendcodespacerange\n"+
+			fmt.Sprintf("%d beginbfchar\n%sendbfchar\n", len(glyphs), cmap.String())+
+			"endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n"))
+		fontRefs = append(fontRefs, w.object(fmt.Sprintf(
+			"<< /Type /Font /Subtype /Type3 /FontBBox [0 -4 12 12] /FontMatrix [%s 0 0 %s 0 0] "+
+				"/CharProcs << %s>> /Encoding << /Type /Encoding /Differences [0 %s] >> "+
+				"/FirstChar 0 /LastChar %d /Widths [%s] /Resources << >> /ToUnicode %d 0 R >>",
+			pdfNum(1.0/pngFaceSize), pdfNum(1.0/pngFaceSize), procs.String(), diffs.String(), len(glyphs)-1, widths.String(), toUnicode)))
+	}
+
+	// Shared resources.
+	var res strings.Builder
+	res.WriteString("<< /Font << ")
+	for i, ref := range fontRefs {
+		fmt.Fprintf(&res, "/T%d %d 0 R ", i+1, ref)
+	}
+	res.WriteString(">> /ExtGState << ")
+	alphas := make([]int, 0, len(d.states))
+	for a := range d.states {
+		alphas = append(alphas, int(a))
+	}
+	sort.Ints(alphas)
+	for _, a := range alphas {
+		alpha := pdfNum(float64(a) / 255)
+		fmt.Fprintf(&res, "/%s << /ca %s /CA %s >> ", d.states[uint8(a)], alpha, alpha)
+	}
+	res.WriteString(">> >>")
+	resources := w.object(res.String())
+
+	var kids strings.Builder
+	for _, p := range d.pages {
+		content, err := deflate(p.content)
+		if err != nil {
+			return nil, err
+		}
+		contentRef := w.stream([]string{"/Filter /FlateDecode"}, content)
+		pageRef := w.object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+			pages, pdfNum(p.width), pdfNum(p.height), resources, contentRef))
+		fmt.Fprintf(&kids, "%d 0 R ", pageRef)
+	}
+	w.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.pages)))
+	w.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	info := w.object(fmt.Sprintf("<< /Title <%s> /Producer (ganttgen) /CreationDate (D:%s) >>",
+		utf16Hex("\ufeff"+title), time.Now().Format("20060102150405")))
+	return w.finish(catalog, info), nil
+}
+
+// pdfWriter numbers objects and writes them with a cross-reference table.
+type pdfWriter struct {
+	objects [][]byte
+}
+
+func (w *pdfWriter) reserve() int {
+	w.objects = append(w.objects, nil)
+	return len(w.objects)
+}
+
+func (w *pdfWriter) set(ref int, body string) {
+	w.objects[ref-1] = []byte(body)
+}
+
+func (w *pdfWriter) object(body string) int {
+	ref := w.reserve()
+	w.set(ref, body)
+	return ref
+}
+
+func (w *pdfWriter) stream(entries []string, data []byte) int {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<< /Length %d %s>>\nstream\n", len(data), strings.Join(append(entries, ""), " "))
+	b.Write(data)
+	b.WriteString("\nendstream")
+	ref := w.reserve()
+	w.objects[ref-1] = b.Bytes()
+	return ref
+}
+
+func (w *pdfWriter) finish(root, info int) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(w.objects))
+	for i, obj := range w.objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", i+1)
+		b.Write(obj)
+		b.WriteString("\nendobj\n")
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(w.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.objects)+1, root, info, xref)
+	return b.Bytes()
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// utf16Hex encodes s as big-endian UTF-16 hex digits.
+func utf16Hex(s string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// pdfNum formats a number with at most three decimals.
+func pdfNum(v float64) string {
+	s := strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+func TestBuildPDFPaginatesDatesAndRows(t *testing.T) {
+	tasks := []model.Task{{Name: "設計", IsHeading: true}}
+	for i := 0; i < 40; i++ {
+		start := day(2024, time.April, 1).AddDate(0, 0, i*3)
+		tasks = append(tasks, model.Task{Name: fmt.Sprintf("Task %d", i+1), ComputedStart: start, ComputedEnd: start.AddDate(0, 0, 4)})
+	}
+	opts := Options{From: day(2024, time.April, 1), To: day(2024, time.July, 31), PageSize: "a4"}
+	data, err := BuildPDF(tasks, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF file")
+	}
+
+	ctx := mustContext(t, tasks, opts)
+	st, err := newChartStyle(ctx, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	areaW := (pageSizes["a4"][0] - 2*pdfMargin) / pdfScale
+	areaH := (pageSizes["a4"][1] - 2*pdfMargin - pdfFooter) / pdfScale
+	days := int((areaW - 2*chartPadding - st.sideWidth()) / st.cell)
+	rows := int((areaH - chartHeaderHeight - chartPadding) / rowHeight(ctx))
+	wantPages := ((len(ctx.Days) + days - 1) / days) * ((len(ctx.Rows) + rows - 1) / rows)
+	if wantPages < 4 {
+		t.Fatalf("test data should need several pages in both directions, got %d", wantPages)
+	}
+	if got := bytes.Count(data, []byte("/Type /Page ")); got != wantPages {
+		t.Fatalf("expected %d pages, got %d", wantPages, got)
+	}
+	// The embedded font maps its codes back to Unicode, including Japanese.
+	if !bytes.Contains(data, []byte("<8A2D>")) || !bytes.Contains(data, []byte("/Subtype /Type3")) {
+		t.Fatalf("expected embedded Type3 font with a ToUnicode entry for 設")
+	}
+}
+
+func TestBuildPDFRejectsUnknownPageSize(t *testing.T) {
+	tasks := []model.Task{{Name: "A", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)}}
+	if _, err := BuildPDF(tasks, Options{PageSize: "letter"}); err == nil || !strings.Contains(err.Error(), "letter") {
+		t.Fatalf("expected page size error, got %v", err)
+	}
+}
+
+func TestChunkRangeKeepsHeadingsWithTheirRows(t *testing.T) {
+	headings := map[int]bool{2: true}
+	got := chunkRange(6, 3, func(end int) bool { return headings[end-1] })
+	want := [][2]int{{0, 2}, {2, 5}, {5, 6}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestContextSliceClipsBars(t *testing.T) {
+	tasks := []model.Task{{Name: "A", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 12)}}
+	ctx := mustContext(t, tasks, Options{From: day(2024, time.June, 1), To: day(2024, time.June, 20)})
+	page := ctx.slice(5, 10, 0, 1)
+	bars := page.Rows[0].Task.Plans
+	if len(bars) != 1 || bars[0].StartIndex != 0 || bars[0].Span != 5 {
+		t.Fatalf("unexpected clipped bars: %#v", bars)
+	}
+	if ctx.Rows[0].Task.Plans[0].StartIndex != 2 {
+		t.Fatalf("slicing must not modify the original rows")
+	}
+}
@@ -0,0 +1,143 @@
+package renderer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// BuildPlantUML converts scheduled tasks into a PlantUML gantt diagram (@startgantt).
+// Weekends and holidays from the calendar become closed days, so durations are given in
+// workdays as the scheduler counts them. Heading rows become separators. A dependency is
+// written as "starts at [x]'s end", which PlantUML resolves to the same day as the
+// scheduler; tasks that start later for another reason get their date instead. Cancelled
+// and display-only rows are left out. Only Options.Title is used.
+func BuildPlantUML(tasks []model.Task, opts Options) (string, error) {
+	ids := taskAliases(tasks)
+	ends := make(map[string]time.Time)
+	var (
+		body     strings.Builder
+		headings []string
+		start    time.Time
+		end      time.Time
+		count    int
+	)
+	for _, t := range tasks {
+		if t.IsHeading {
+			headings = append(headings[:min(t.Level, len(headings))], t.Name)
+			fmt.Fprintf(&body, "-- %s --\n", plantUMLText(strings.Join(headings, " / ")))
+			continue
+		}
+		if t.DisplayOnly || t.IsCancelled() {
+			continue
+		}
+		if count == 0 || t.ComputedStart.Before(start) {
+			start = t.ComputedStart
+		}
+		if count == 0 || t.ComputedEnd.After(end) {
+			end = t.ComputedEnd
+		}
+		count++
+		id := ids[t.Key()]
+		subject := fmt.Sprintf("[%s] as [%s]", plantUMLText(t.Name), id)
+		if t.Milestone {
+			// Tasks after a milestone keep their dates: it is not in ends, as PlantUML
+			// would start them on the milestone day itself.
+			fmt.Fprintf(&body, "%s happens %s\n", subject, t.ComputedStart.Format("2006-01-02"))
+			continue
+		}
+		segments := t.PlanSegments()
+		days := 0
+		for _, seg := range segments {
+			days += calendar.CountWorkdays(seg.Start, seg.End)
+		}
+		fmt.Fprintf(&body, "%s requires %d days\n", subject, days)
+		if deps := plantUMLAfter(t, ends); deps != nil {
+			for _, dep := range deps {
+				fmt.Fprintf(&body, "[%s] starts at [%s]'s end\n", id, ids[dep])
+			}
+		} else {
+			fmt.Fprintf(&body, "[%s] starts %s\n", id, t.ComputedStart.Format("2006-01-02"))
+		}
+		// Days between the worked segments are pauses; PlantUML does not count them.
+		for i := 1; i < len(segments); i++ {
+			for day := segments[i-1].End.AddDate(0, 0, 1); day.Before(segments[i].Start); day = day.AddDate(0, 0, 1) {
+				if calendar.IsWorkday(day) {
+					fmt.Fprintf(&body, "[%s] pauses on %s\n", id, day.Format("2006-01-02"))
+				}
+			}
+		}
+		if percent, ok := plantUMLCompletion(t); ok {
+			fmt.Fprintf(&body, "[%s] is %d%% completed\n", id, percent)
+		}
+		ends[t.Key()] = t.ComputedEnd
+	}
+	if count == 0 {
+		return "", fmt.Errorf("no schedulable tasks to render")
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = defaultTitle
+	}
+	var b strings.Builder
+	b.WriteString("@startgantt\n")
+	fmt.Fprintf(&b, "title %s\n", plantUMLText(title))
+	fmt.Fprintf(&b, "Project starts %s\n", start.Format("2006-01-02"))
+	for _, d := range calendar.Weekends() {
+		fmt.Fprintf(&b, "%s are closed\n", strings.ToLower(d.String()))
+	}
+	closed, open := calendar.Exceptions(start, end)
+	for _, d := range closed {
+		fmt.Fprintf(&b, "%s is closed\n", d.Format("2006-01-02"))
+	}
+	for _, d := range open {
+		fmt.Fprintf(&b, "%s is open\n", d.Format("2006-01-02"))
+	}
+	b.WriteString(body.String())
+	b.WriteString("@endgantt\n")
+	return b.String(), nil
+}
+
+// plantUMLAfter returns the dependencies of t ordered by their end, latest last, when
+// t starts on the first workday after the latest of them; nil otherwise. PlantUML keeps
+// the last start constraint, so the latest dependency decides the date.
+func plantUMLAfter(t model.Task, ends map[string]time.Time) []string {
+	if len(t.DependsOn) == 0 {
+		return nil
+	}
+	deps := append([]string(nil), t.DependsOn...)
+	for _, dep := range deps {
+		if _, ok := ends[dep]; !ok {
+			return nil
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return ends[deps[i]].Before(ends[deps[j]]) })
+	if !calendar.NextWorkdayAfter(ends[deps[len(deps)-1]]).Equal(calendar.DateOnly(t.ComputedStart)) {
+		return nil
+	}
+	return deps
+}
+
+// plantUMLCompletion returns the completion to show: the progress column, or 100 for
+// completed tasks.
+func plantUMLCompletion(t model.Task) (int, bool) {
+	switch {
+	case t.IsCompleted():
+		return 100, true
+	case t.ProgressPercent != nil:
+		return *t.ProgressPercent, true
+	}
+	return 0, false
+}
+
+// plantUMLText keeps a name inside its brackets: square brackets become parentheses and
+// line breaks become spaces.
+func plantUMLText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer("[", "(", "]", ")").Replace(s)
+}
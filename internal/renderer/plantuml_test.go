@@ -0,0 +1,61 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+func TestBuildPlantUMLWritesCalendarAndConstraints(t *testing.T) {
+	calendar.SetHolidays([]time.Time{day(2024, time.June, 12)})
+	calendar.SetExtraWorkdays([]time.Time{day(2024, time.June, 15)})
+	t.Cleanup(func() { calendar.SetHolidays(nil) })
+	t.Cleanup(func() { calendar.SetExtraWorkdays(nil) })
+	progress := 40
+	tasks := []model.Task{
+		{Name: "設計 [v2]", IsHeading: true},
+		{ID: "a", Name: "要件", Status: "完了", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 4)},
+		{ID: "b", Name: "画面", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 5)},
+		{ID: "c", Name: "設計書", ProgressPercent: &progress, DependsOn: []string{"b", "a"}, ComputedStart: day(2024, time.June, 6), ComputedEnd: day(2024, time.June, 13)},
+		{Name: "実装", IsHeading: true},
+		{ID: "d", Name: "実装", DependsOn: []string{"c"}, ComputedStart: day(2024, time.June, 17), ComputedEnd: day(2024, time.June, 26),
+			ComputedSegments: []model.Interval{
+				{Start: day(2024, time.June, 17), End: day(2024, time.June, 18)},
+				{Start: day(2024, time.June, 20), End: day(2024, time.June, 26)},
+			}},
+		{ID: "r", Name: "リリース", Milestone: true, DependsOn: []string{"d"}, ComputedStart: day(2024, time.June, 27), ComputedEnd: day(2024, time.June, 27)},
+	}
+	out, err := BuildPlantUML(tasks, Options{Title: "計画"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `@startgantt
+title 計画
+Project starts 2024-06-03
+sunday are closed
+saturday are closed
+2024-06-12 is closed
+2024-06-15 is open
+-- 設計 (v2) --
+[要件] as [a] requires 2 days
+[a] starts 2024-06-03
+[a] is 100% completed
+[画面] as [b] requires 3 days
+[b] starts 2024-06-03
+[設計書] as [c] requires 5 days
+[c] starts at [a]'s end
+[c] starts at [b]'s end
+[c] is 40% completed
+-- 実装 --
+[実装] as [d] requires 7 days
+[d] starts 2024-06-17
+[d] pauses on 2024-06-19
+[リリース] as [r] happens 2024-06-27
+@endgantt
+`
+	if out != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+}
@@ -0,0 +1,359 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/bitmapfont/v3"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"ganttgen/internal/model"
+)
+
+// DefaultScale is the PNG pixel ratio used when Options.Scale is zero.
+const DefaultScale = 1
+
+// maxPNGPixels bounds the image size so that a long timeline cannot exhaust memory.
+const maxPNGPixels = 200_000_000
+
+// pngFace is the bundled bitmap font. It covers Latin and Japanese glyphs at 12px
+// (6px half-width, 12px full-width) and is scaled for other sizes.
+var pngFace = bitmapfont.FaceEA
+
+const pngFaceSize = 12
+
+// BuildPNG renders the chart as a PNG image without a browser, drawing the same layout
+// as BuildSVG. Options.Scale multiplies the pixel size (e.g. 2 for high-DPI screens).
+func BuildPNG(tasks []model.Task, opts Options) ([]byte, error) {
+	img, err := rasterizeChart(tasks, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func rasterizeChart(tasks []model.Task, opts Options) (*image.RGBA, error) {
+	scale := opts.Scale
+	if scale == 0 {
+		scale = DefaultScale
+	}
+	if scale < 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		return nil, fmt.Errorf("scale must be positive, got %v", opts.Scale)
+	}
+	ctx, err := buildContext(tasks, opts)
+	if err != nil {
+		return nil, err
+	}
+	c, err := layoutChart(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	w, h := int(math.Ceil(c.Width*scale)), int(math.Ceil(c.Height*scale))
+	if w*h > maxPNGPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large; narrow the date range or lower the scale", w, h)
+	}
+	r := rasterizer{img: image.NewRGBA(image.Rect(0, 0, w, h)), scale: scale}
+	for _, m := range c.Marks {
+		if err := r.draw(m); err != nil {
+			return nil, err
+		}
+	}
+	return r.img, nil
+}
+
+// rasterizer draws chart marks onto an RGBA image.
+type rasterizer struct {
+	img   *image.RGBA
+	scale float64
+}
+
+func (r *rasterizer) draw(m mark) error {
+	switch m.Kind {
+	case markRect:
+		if m.Fill != "" {
+			fill, err := parseColor(m.Fill, m.Opacity)
+			if err != nil {
+				return err
+			}
+			r.fillRoundRect(m.X, m.Y, m.W, m.H, m.Radius, fill)
+		}
+		if m.Stroke != "" {
+			stroke, err := parseColor(m.Stroke, m.Opacity)
+			if err != nil {
+				return err
+			}
+			r.strokeRect(m, stroke)
+		}
+	case markLine:
+		stroke, err := parseColor(m.Stroke, m.Opacity)
+		if err != nil {
+			return err
+		}
+		sw := max(m.StrokeWidth, 1)
+		// Chart lines are horizontal or vertical; draw them as thin rectangles.
+		x, y, w, h := m.X, m.Y, m.W, m.H
+		if w == 0 {
+			x, w = x-sw/2, sw
+		} else {
+			y, h = y-sw/2, sw
+		}
+		r.fillRoundRect(x, y, w, h, 0, stroke)
+	case markText:
+		fill, err := parseColor(m.Fill, m.Opacity)
+		if err != nil {
+			return err
+		}
+		r.text(m, fill)
+	}
+	return nil
+}
+
+// fillRoundRect fills an anti-aliased rectangle given in chart coordinates.
+func (r *rasterizer) fillRoundRect(x, y, w, h, radius float64, c color.Color) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	s := r.scale
+	x0, y0, x1, y1 := x*s, y*s, (x+w)*s, (y+h)*s
+	rad := min(radius*s, (x1-x0)/2, (y1-y0)/2)
+	bounds := image.Rect(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x1)), int(math.Ceil(y1))).Intersect(r.img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	ox, oy := float32(bounds.Min.X), float32(bounds.Min.Y)
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	pt := func(px, py float64) (float32, float32) { return float32(px) - ox, float32(py) - oy }
+	z.MoveTo(pt(x0+rad, y0))
+	z.LineTo(pt(x1-rad, y0))
+	if rad > 0 {
+		cx, cy := pt(x1, y0)
+		ex, ey := pt(x1, y0+rad)
+		z.QuadTo(cx, cy, ex, ey)
+	}
+	z.LineTo(pt(x1, y1-rad))
+	if rad > 0 {
+		cx, cy := pt(x1, y1)
+		ex, ey := pt(x1-rad, y1)
+		z.QuadTo(cx, cy, ex, ey)
+	}
+	z.LineTo(pt(x0+rad, y1))
+	if rad > 0 {
+		cx, cy := pt(x0, y1)
+		ex, ey := pt(x0, y1-rad)
+		z.QuadTo(cx, cy, ex, ey)
+	}
+	z.LineTo(pt(x0, y0+rad))
+	if rad > 0 {
+		cx, cy := pt(x0, y0)
+		ex, ey := pt(x0+rad, y0)
+		z.QuadTo(cx, cy, ex, ey)
+	}
+	z.ClosePath()
+	z.Draw(r.img, bounds, image.NewUniform(c), image.Point{})
+}
+
+// strokeRect outlines a rectangle, dashed when the mark asks for it.
+func (r *rasterizer) strokeRect(m mark, c color.Color) {
+	sw := max(m.StrokeWidth, 1)
+	edges := [][4]float64{
+		{m.X, m.Y, m.W, 0},
+		{m.X, m.Y + m.H, m.W, 0},
+		{m.X, m.Y, 0, m.H},
+		{m.X + m.W, m.Y, 0, m.H},
+	}
+	for _, e := range edges {
+		x, y, w, h := e[0], e[1], e[2], e[3]
+		length, horizontal := h, false
+		if h == 0 {
+			length, horizontal = w, true
+		}
+		dash, gap := length, 0.0
+		if m.Dashed {
+			dash, gap = 4, 3
+		}
+		for pos := 0.0; pos < length; pos += dash + gap {
+			seg := min(dash, length-pos)
+			if horizontal {
+				r.fillRoundRect(x+pos, y-sw/2, seg, sw, 0, c)
+			} else {
+				r.fillRoundRect(x-sw/2, y+pos, sw, seg, 0, c)
+			}
+		}
+	}
+}
+
+// text draws a label with the bitmap font: glyphs are rendered at their native size
+// into a mask, which is then scaled to the requested font size.
+func (r *rasterizer) text(m mark, c color.Color) {
+	if m.Text == "" {
+		return
+	}
+	metrics := pngFace.Metrics()
+	ascent, descent := metrics.Ascent.Ceil(), metrics.Descent.Ceil()
+	width := font.MeasureString(pngFace, m.Text).Ceil()
+	if m.Bold {
+		width++
+	}
+	if width <= 0 {
+		return
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, ascent+descent))
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: pngFace, Dot: fixed.P(0, ascent)}
+	d.DrawString(m.Text)
+	if m.Bold {
+		d.Dot = fixed.P(1, ascent)
+		d.DrawString(m.Text)
+	}
+
+	k := m.FontSize / pngFaceSize * r.scale
+	dw, dh := float64(width)*k, float64(ascent+descent)*k
+	x := m.X * r.scale
+	switch m.Anchor {
+	case "middle":
+		x -= dw / 2
+	case "end":
+		x -= dw
+	}
+	y := m.Y*r.scale - float64(ascent)*k
+	target := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+dw)), int(math.Round(y+dh)))
+	scaled := image.NewAlpha(image.Rect(0, 0, target.Dx(), target.Dy()))
+	var interp draw.Interpolator = draw.ApproxBiLinear
+	if k == math.Trunc(k) {
+		// Integer factors keep the pixel font crisp.
+		interp = draw.NearestNeighbor
+	}
+	interp.Scale(scaled, scaled.Bounds(), mask, mask.Bounds(), draw.Src, nil)
+	draw.DrawMask(r.img, target, image.NewUniform(c), image.Point{}, scaled, image.Point{}, draw.Over)
+}
+
+// parseColor converts a CSS color accepted by model.IsValidColor into an RGBA value.
+// opacity (0 meaning opaque) multiplies the alpha.
+func parseColor(value string, opacity float64) (color.Color, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	var c color.NRGBA
+	var ok bool
+	switch {
+	case strings.HasPrefix(v, "#"):
+		c, ok = parseHexColor(v[1:])
+	case strings.HasPrefix(v, "rgb"):
+		c, ok = parseColorFunc(v, false)
+	case strings.HasPrefix(v, "hsl"):
+		c, ok = parseColorFunc(v, true)
+	case v == "transparent":
+		c, ok = color.NRGBA{}, true
+	default:
+		var named color.RGBA
+		named, ok = colornames.Map[v]
+		c = color.NRGBA{R: named.R, G: named.G, B: named.B, A: named.A}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unsupported color %q", value)
+	}
+	if opacity > 0 {
+		c.A = uint8(math.Round(float64(c.A) * opacity))
+	}
+	return c, nil
+}
+
+func parseHexColor(hex string) (color.NRGBA, bool) {
+	switch len(hex) {
+	case 3, 4:
+		var expanded strings.Builder
+		for _, ch := range hex {
+			expanded.WriteRune(ch)
+			expanded.WriteRune(ch)
+		}
+		hex = expanded.String()
+	case 6, 8:
+	default:
+		return color.NRGBA{}, false
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, true
+}
+
+// parseColorFunc parses rgb()/rgba() or, when hsl is set, hsl()/hsla().
+func parseColorFunc(v string, hsl bool) (color.NRGBA, bool) {
+	open, closing := strings.IndexByte(v, '('), strings.LastIndexByte(v, ')')
+	if open < 0 || closing < open {
+		return color.NRGBA{}, false
+	}
+	parts := strings.Fields(strings.ReplaceAll(v[open+1:closing], ",", " "))
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, false
+	}
+	nums := make([]float64, len(parts))
+	for i, p := range parts {
+		percent := strings.HasSuffix(p, "%")
+		n, err := strconv.ParseFloat(strings.TrimSuffix(p, "%"), 64)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		switch {
+		case i == 3 && percent:
+			n /= 100
+		case i == 3:
+		case percent && hsl:
+			n /= 100
+		case percent:
+			n = n * 255 / 100
+		}
+		nums[i] = n
+	}
+	alpha := 1.0
+	if len(nums) == 4 {
+		alpha = nums[3]
+	}
+	r, g, b := nums[0], nums[1], nums[2]
+	if hsl {
+		r, g, b = hslToRGB(nums[0], nums[1], nums[2])
+	}
+	clamp := func(f float64) uint8 { return uint8(math.Round(min(max(f, 0), 255))) }
+	return color.NRGBA{R: clamp(r), G: clamp(g), B: clamp(b), A: clamp(alpha * 255)}, true
+}
+
+// hslToRGB converts hue (degrees), saturation and lightness (0..1) to 0..255 channels.
+func hslToRGB(h, s, l float64) (float64, float64, float64) {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	if s == 0 {
+		return l * 255, l * 255, l * 255
+	}
+	q := l + s - l*s
+	if l < 0.5 {
+		q = l * (1 + s)
+	}
+	p := 2*l - q
+	channel := func(t float64) float64 {
+		t = math.Mod(t+1, 1)
+		switch {
+		case t < 1.0/6:
+			return p + (q-p)*6*t
+		case t < 0.5:
+			return q
+		case t < 2.0/3:
+			return p + (q-p)*(2.0/3-t)*6
+		}
+		return p
+	}
+	return channel(h+1.0/3) * 255, channel(h) * 255, channel(h-1.0/3) * 255
+}
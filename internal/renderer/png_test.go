@@ -0,0 +1,87 @@
+package renderer
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+func TestBuildPNGDrawsChartAtScale(t *testing.T) {
+	tasks := []model.Task{
+		{Name: "設計", IsHeading: true},
+		{Name: "画面設計", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 5)},
+	}
+	opts := Options{From: day(2024, time.June, 3), To: day(2024, time.June, 9), Theme: Theme{Accent: "#00ff00"}}
+	one, err := BuildPNG(tasks, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts.Scale = 2
+	two, err := BuildPNG(tasks, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img1, err := png.Decode(bytes.NewReader(one))
+	if err != nil {
+		t.Fatalf("invalid png: %v", err)
+	}
+	img2, err := png.Decode(bytes.NewReader(two))
+	if err != nil {
+		t.Fatalf("invalid png: %v", err)
+	}
+	b1, b2 := img1.Bounds(), img2.Bounds()
+	if b2.Dx() != 2*b1.Dx() || b2.Dy() != 2*b1.Dy() {
+		t.Fatalf("expected scale 2 to double %v, got %v", b1, b2)
+	}
+
+	c, err := layoutChart(mustContext(t, tasks, opts), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, m := range c.Marks {
+		if m.Kind == markRect && m.Fill == "#00ff00" {
+			px := color.NRGBAModel.Convert(img2.At(int((m.X+m.W/2)*2), int((m.Y+m.H/2)*2))).(color.NRGBA)
+			if px.G != 0xff || px.R != 0 {
+				t.Fatalf("expected the plan bar color in the middle of the bar, got %v", px)
+			}
+			return
+		}
+	}
+	t.Fatalf("plan bar not laid out")
+}
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]color.NRGBA{
+		"#4c6fff":             {R: 0x4c, G: 0x6f, B: 0xff, A: 0xff},
+		"#f00":                {R: 0xff, A: 0xff},
+		"teal":                {G: 0x80, B: 0x80, A: 0xff},
+		"rgb(0, 128, 255)":    {G: 128, B: 255, A: 0xff},
+		"rgba(0,0,0,0.5)":     {A: 128},
+		"hsl(120, 100%, 50%)": {G: 0xff, A: 0xff},
+	}
+	for in, want := range cases {
+		got, err := parseColor(in, 0)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("%q: want %v, got %v", in, want, got)
+		}
+	}
+	if _, err := parseColor("notacolor", 0); err == nil {
+		t.Fatalf("expected error for unknown color name")
+	}
+}
+
+func mustContext(t *testing.T, tasks []model.Task, opts Options) renderContext {
+	t.Helper()
+	ctx, err := buildContext(tasks, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ctx
+}
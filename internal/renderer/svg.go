@@ -0,0 +1,101 @@
+package renderer
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"ganttgen/internal/model"
+)
+
+// BuildSVG renders the chart as a standalone SVG image. It uses the same rows as the
+// HTML page; Options.From/To limit the dates and Options.SideColumns picks the columns.
+func BuildSVG(tasks []model.Task, opts Options) (string, error) {
+	ctx, err := buildContext(tasks, opts)
+	if err != nil {
+		return "", err
+	}
+	c, err := layoutChart(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="%s">`+"\n",
+		svgNum(c.Width), svgNum(c.Height), svgNum(c.Width), svgNum(c.Height), html.EscapeString(chartFontFamily))
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(ctx.Title))
+	for _, m := range c.Marks {
+		writeSVGMark(&b, m)
+	}
+	b.WriteString("</svg>\n")
+	return b.String(), nil
+}
+
+func writeSVGMark(b *strings.Builder, m mark) {
+	var attrs []string
+	attr := func(name, value string) { attrs = append(attrs, name+`="`+html.EscapeString(value)+`"`) }
+	var tag, body string
+	switch m.Kind {
+	case markRect:
+		tag = "rect"
+		attr("x", svgNum(m.X))
+		attr("y", svgNum(m.Y))
+		attr("width", svgNum(m.W))
+		attr("height", svgNum(m.H))
+		if m.Radius > 0 {
+			attr("rx", svgNum(m.Radius))
+		}
+	case markLine:
+		tag = "line"
+		attr("x1", svgNum(m.X))
+		attr("y1", svgNum(m.Y))
+		attr("x2", svgNum(m.X+m.W))
+		attr("y2", svgNum(m.Y+m.H))
+	case markText:
+		tag = "text"
+		attr("x", svgNum(m.X))
+		attr("y", svgNum(m.Y))
+		attr("font-size", svgNum(m.FontSize))
+		if m.Anchor != "start" {
+			attr("text-anchor", m.Anchor)
+		}
+		if m.Bold {
+			attr("font-weight", "bold")
+		}
+		body = html.EscapeString(m.Text)
+	}
+	fill := m.Fill
+	if fill == "" {
+		fill = "none"
+	}
+	attr("fill", fill)
+	if m.Stroke != "" {
+		attr("stroke", m.Stroke)
+		if m.StrokeWidth > 0 {
+			attr("stroke-width", svgNum(m.StrokeWidth))
+		}
+		if m.Dashed {
+			attr("stroke-dasharray", "4 3")
+		}
+	}
+	if m.Opacity > 0 {
+		attr("opacity", svgNum(m.Opacity))
+	}
+	open := "<" + tag + " " + strings.Join(attrs, " ")
+	// Text keeps its full value as a tooltip only when it was truncated.
+	if m.Title != "" && (m.Kind != markText || m.Title != m.Text) {
+		fmt.Fprintf(b, "%s><title>%s</title>%s</%s>\n", open, html.EscapeString(m.Title), body, tag)
+		return
+	}
+	if body != "" {
+		fmt.Fprintf(b, "%s>%s</%s>\n", open, body, tag)
+		return
+	}
+	b.WriteString(open + "/>\n")
+}
+
+// svgNum formats a coordinate with at most two decimals.
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
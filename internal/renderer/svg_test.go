@@ -0,0 +1,83 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/model"
+)
+
+func TestBuildSVGRendersBarsAndSections(t *testing.T) {
+	progress := 50
+	tasks := []model.Task{
+		{Name: "Design & <Build>", IsHeading: true},
+		{
+			Name:                "Task A",
+			Status:              "進行中",
+			ProgressPercent:     &progress,
+			ComputedStart:       day(2024, time.June, 3),
+			ComputedEnd:         day(2024, time.June, 5),
+			ComputedActualStart: ptrTime(day(2024, time.June, 3)),
+			ComputedActualEnd:   ptrTime(day(2024, time.June, 4)),
+		},
+	}
+	svg, err := BuildSVG(tasks, Options{
+		HasProgressColumn: true,
+		Title:             "Plan",
+		From:              day(2024, time.June, 3),
+		To:                day(2024, time.June, 9),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		"<title>Plan</title>",
+		"Design &amp; &lt;Build&gt;",
+		">進行中</text>",
+		">50%</text>",
+		">2024-06</text>",
+		"予定: 2024-06-03 - 2024-06-05 (進捗 50%)",
+		"実績: 2024-06-03 - 2024-06-04",
+		`fill="` + chartWeekend + `"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Fatalf("expected %q in SVG:\n%s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "今日") {
+		t.Fatalf("today line drawn outside the date range")
+	}
+}
+
+func TestBuildSVGClipsToDateRange(t *testing.T) {
+	tasks := []model.Task{
+		{Name: "Early", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 4)},
+		{Name: "Long", ComputedStart: day(2024, time.June, 5), ComputedEnd: day(2024, time.June, 20)},
+	}
+	svg, err := BuildSVG(tasks, Options{From: day(2024, time.June, 10), To: day(2024, time.June, 12), SideColumns: []string{"name"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(svg, "予定: 2024-06-03") {
+		t.Fatalf("bar outside the range was drawn")
+	}
+	if !strings.Contains(svg, "予定: 2024-06-05 - 2024-06-20") {
+		t.Fatalf("clipped bar missing")
+	}
+	if strings.Contains(svg, ">状態</text>") {
+		t.Fatalf("status column drawn although not selected")
+	}
+}
+
+func TestBuildSVGRejectsUnknownSideColumn(t *testing.T) {
+	tasks := []model.Task{{Name: "A", ComputedStart: day(2024, time.June, 3), ComputedEnd: day(2024, time.June, 3)}}
+	_, err := BuildSVG(tasks, Options{SideColumns: []string{"name", "owner"}})
+	if err == nil || !strings.Contains(err.Error(), `"owner"`) {
+		t.Fatalf("expected unknown column error, got %v", err)
+	}
+	if _, err := BuildSVG(tasks, Options{CustomColumns: []string{"owner"}, SideColumns: []string{"owner"}}); err != nil {
+		t.Fatalf("custom column should be accepted: %v", err)
+	}
+}
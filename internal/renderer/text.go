@@ -0,0 +1,428 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// DefaultTextWidth is the width of text output used when Options.TextWidth is zero.
+const DefaultTextWidth = 80
+
+// Layout limits of text output, in terminal columns.
+const (
+	textMinName     = 12
+	textMinTimeline = 10
+	textIndent      = "  "
+	textSeparator   = " │"
+)
+
+// Characters of the text chart.
+const (
+	textPlan      = "█"
+	textRemaining = "▒"
+	textActual    = "▀"
+	textMilestone = "◆"
+	textToday     = "┆"
+	textWeekend   = "·"
+)
+
+// SGR parameters of text output.
+const (
+	sgrBold = "1"
+	sgrDim  = "2"
+)
+
+// textUnit is the span of one timeline column in text output.
+type textUnit struct {
+	legend string
+	// starts reports whether a column starts on d.
+	starts func(d time.Time) bool
+	// major and minor label the two header rows: the label of d and whether d starts it.
+	major, minor func(d time.Time) (string, bool)
+}
+
+// textUnits are the column spans of text output, from the finest.
+var textUnits = []textUnit{
+	{legend: "1 日", starts: func(time.Time) bool { return true }, major: monthLabel, minor: mondayLabel},
+	{legend: "1 週（月曜始まり）", starts: func(d time.Time) bool { return d.Weekday() == time.Monday }, major: monthLabel, minor: mondayLabel},
+	{legend: "1 か月", starts: func(d time.Time) bool { return d.Day() == 1 }, major: yearLabel, minor: func(d time.Time) (string, bool) {
+		return strconv.Itoa(int(d.Month())), d.Day() == 1
+	}},
+	{legend: "四半期", starts: quarterStart, major: yearLabel, minor: func(d time.Time) (string, bool) {
+		return fmt.Sprintf("Q%d", (d.Month()+2)/3), quarterStart(d)
+	}},
+	{legend: "1 年", starts: func(d time.Time) bool { return d.YearDay() == 1 }, major: yearLabel, minor: func(time.Time) (string, bool) {
+		return "", false
+	}},
+}
+
+func monthLabel(d time.Time) (string, bool) { return d.Format("2006-01"), d.Day() == 1 }
+func yearLabel(d time.Time) (string, bool)  { return d.Format("2006"), d.YearDay() == 1 }
+func mondayLabel(d time.Time) (string, bool) {
+	return strconv.Itoa(d.Day()), d.Weekday() == time.Monday
+}
+func quarterStart(d time.Time) bool { return d.Day() == 1 && d.Month()%3 == 1 }
+
+// BuildText renders the chart as text for a terminal: one line per row with block
+// characters on a timeline of one column per day. When the days do not fit in
+// Options.TextWidth, a column spans a week (from Monday), a month, a quarter or a year,
+// whichever is the finest that fits. Section headings indent the rows below them, and
+// actual work is drawn on a second line under the plan. With Options.TextColor the bars
+// take the theme colors as ANSI escapes, and weekends and finished tasks are dimmed.
+func BuildText(tasks []model.Task, opts Options) (string, error) {
+	ctx, err := buildContext(tasks, opts)
+	if err != nil {
+		return "", err
+	}
+	th, err := opts.Theme.palette()
+	if err != nil {
+		return "", err
+	}
+	width := opts.TextWidth
+	if width < 0 {
+		return "", fmt.Errorf("text width must be positive, got %d", width)
+	}
+	if width == 0 {
+		width = DefaultTextWidth
+	}
+
+	names := make([]string, len(ctx.Rows))
+	nameWidth := displayWidth("Task")
+	depth := 0
+	for i, row := range ctx.Rows {
+		switch {
+		case row.Heading != "":
+			names[i] = strings.Repeat(textIndent, row.HeadingLevel) + row.Heading
+			depth = row.HeadingLevel + 1
+		case row.Task != nil:
+			names[i] = strings.Repeat(textIndent, depth) + row.Task.Name
+		default:
+			names[i] = strings.Repeat(textIndent, depth) + row.DisplayOnly
+		}
+		nameWidth = max(nameWidth, displayWidth(names[i]))
+	}
+	nameWidth = min(nameWidth, max(textMinName, width/3))
+	if width-nameWidth-displayWidth(textSeparator) < textMinTimeline {
+		nameWidth = max(displayWidth("Task"), width-displayWidth(textSeparator)-textMinTimeline)
+	}
+
+	tc := textChart{pen: textPen{color: opts.TextColor}, theme: th, todayColumn: -1}
+	timelineWidth := width - nameWidth - displayWidth(textSeparator)
+	for u, unit := range textUnits {
+		tc.unit, tc.columns = unit, nil
+		tc.daily = u == 0
+		tc.dayColumn = make([]int, len(ctx.Days))
+		for i, d := range ctx.Days {
+			if i == 0 || unit.starts(d) {
+				tc.columns = append(tc.columns, d)
+			}
+			tc.dayColumn[i] = len(tc.columns) - 1
+		}
+		if len(tc.columns) <= timelineWidth {
+			break
+		}
+	}
+	if ctx.ShowToday {
+		tc.todayColumn = tc.dayColumn[ctx.TodayIndex]
+	}
+
+	var b strings.Builder
+	line := func(name string, nameCodes []string, timeline string) {
+		s := tc.pen.paint(fitText(name, nameWidth), nameCodes...) + tc.pen.paint(textSeparator, sgrDim) + timeline
+		b.WriteString(strings.TrimRight(s, " ") + "\n")
+	}
+	b.WriteString(tc.pen.paint(truncateWidth(ctx.Title, width), sgrBold) + "\n")
+	var major, minor []textLabel
+	for i, d := range ctx.Days {
+		if text, ok := tc.unit.major(d); ok || i == 0 {
+			major = append(major, textLabel{column: tc.dayColumn[i], text: text})
+		}
+		if text, ok := tc.unit.minor(d); (ok || i == 0) && text != "" {
+			minor = append(minor, textLabel{column: tc.dayColumn[i], text: text})
+		}
+	}
+	line("", nil, tc.labels(major, sgrBold))
+	line("Task", []string{sgrBold}, tc.labels(minor))
+
+	var hasProgress, hasMilestone bool
+	for i, row := range ctx.Rows {
+		switch {
+		case row.Heading != "":
+			codes := []string{sgrBold}
+			if row.HeadingMuted {
+				codes = append(codes, sgrDim)
+			}
+			line(names[i], codes, tc.cells(nil))
+		case row.Task != nil:
+			t := row.Task
+			hasProgress = hasProgress || (t.HasProgress && !t.Milestone)
+			hasMilestone = hasMilestone || t.Milestone
+			var codes []string
+			if t.Cancelled {
+				codes = []string{sgrDim}
+			}
+			line(names[i], codes, tc.cells(tc.planCells(t)))
+			if len(t.Actuals) > 0 {
+				line("", nil, tc.cells(tc.actualCells(t)))
+			}
+		default:
+			line(names[i], []string{sgrDim}, tc.cells(nil))
+		}
+	}
+
+	legend := []string{tc.pen.paint(textPlan, textColor(th.Accent, defaultTheme.Accent)) + " 予定"}
+	if hasProgress {
+		legend = append(legend, tc.pen.paint(textRemaining, textColor(th.ProgressRemaining, defaultTheme.ProgressRemaining))+" 残り")
+	}
+	if ctx.HasActual {
+		legend = append(legend, tc.pen.paint(textActual, textColor(th.Actual, defaultTheme.Actual))+" 実績")
+	}
+	if hasMilestone {
+		legend = append(legend, textMilestone+" マイルストーン")
+	}
+	if ctx.ShowToday {
+		legend = append(legend, tc.pen.paint(textToday, textColor(th.Today, defaultTheme.Today))+" 今日 "+formatDate(ctx.Days[ctx.TodayIndex]))
+	}
+	legend = append(legend, "1 列 = "+tc.unit.legend)
+	// The legend wraps to stay within the width.
+	b.WriteString("\n")
+	lineWidth := 0
+	for i, item := range legend {
+		w := visibleWidth(item)
+		if i > 0 && lineWidth+2+w > width {
+			b.WriteString("\n")
+			lineWidth = 0
+		} else if i > 0 {
+			b.WriteString("  ")
+			lineWidth += 2
+		}
+		b.WriteString(item)
+		lineWidth += w
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// textChart is the timeline of text output: each column spans a day or a coarser unit.
+type textChart struct {
+	pen   textPen
+	theme Theme
+	unit  textUnit
+	// daily is set when a column is one day, so that weekends can be shown.
+	daily bool
+	// columns holds the first day of each column, and dayColumn the column of each day of
+	// the render context.
+	columns     []time.Time
+	dayColumn   []int
+	todayColumn int
+}
+
+// textLabel is a header label starting at a column.
+type textLabel struct {
+	column int
+	text   string
+}
+
+// labels writes the labels on the timeline, skipping those that would overlap the
+// previous one or run past the last column.
+func (tc textChart) labels(labels []textLabel, codes ...string) string {
+	var b strings.Builder
+	cursor, next := 0, 0
+	for _, l := range labels {
+		if l.column < next || l.column+len(l.text) > len(tc.columns) {
+			continue
+		}
+		b.WriteString(strings.Repeat(" ", l.column-cursor) + tc.pen.paint(l.text, codes...))
+		cursor = l.column + len(l.text)
+		next = cursor + 1
+	}
+	return b.String()
+}
+
+// cells joins the cells of a row, filling the columns without a bar with the today
+// marker, weekend dots or spaces.
+func (tc textChart) cells(bars map[int]string) string {
+	var b strings.Builder
+	for c, d := range tc.columns {
+		switch cell, ok := bars[c]; {
+		case ok:
+			b.WriteString(cell)
+		case c == tc.todayColumn:
+			b.WriteString(tc.pen.paint(textToday, textColor(tc.theme.Today, defaultTheme.Today)))
+		case tc.daily && !calendar.IsWorkday(d):
+			b.WriteString(tc.pen.paint(textWeekend, sgrDim))
+		default:
+			b.WriteString(" ")
+		}
+	}
+	return b.String()
+}
+
+// planCells draws the plan bars of a task. The first columns up to the progress are
+// solid and the rest shaded; milestones are a diamond.
+func (tc textChart) planCells(t *renderTask) map[int]string {
+	var planned []int
+	for _, bar := range t.Plans {
+		for i := bar.StartIndex; i < bar.StartIndex+bar.Span; i++ {
+			if c := tc.dayColumn[i]; !slices.Contains(planned, c) {
+				planned = append(planned, c)
+			}
+		}
+	}
+	slices.Sort(planned)
+	done := len(planned)
+	if t.HasProgress {
+		done = int(math.Round(float64(len(planned)) * float64(min(max(t.ProgressPercent, 0), 100)) / 100))
+	}
+	fill := textColor(tc.theme.Accent, defaultTheme.Accent)
+	switch {
+	case t.Blocked:
+		fill = textColor(chartBlocked, chartBlocked)
+	case t.StatusColor != "":
+		fill = textColor(string(t.StatusColor), tc.theme.Accent)
+	}
+	cells := make(map[int]string, len(planned))
+	for i, c := range planned {
+		char, color := textPlan, fill
+		switch {
+		case t.Milestone:
+			char = textMilestone
+		case i >= done:
+			char, color = textRemaining, textColor(tc.theme.ProgressRemaining, defaultTheme.ProgressRemaining)
+		}
+		cells[c] = tc.pen.paint(char, tc.barCodes(c, t.Cancelled, color)...)
+	}
+	return cells
+}
+
+// actualCells draws the actual work periods of a task.
+func (tc textChart) actualCells(t *renderTask) map[int]string {
+	cells := make(map[int]string)
+	color := textColor(tc.theme.Actual, defaultTheme.Actual)
+	for _, bar := range t.Actuals {
+		for i := bar.StartIndex; i < bar.StartIndex+bar.Span; i++ {
+			c := tc.dayColumn[i]
+			cells[c] = tc.pen.paint(textActual, tc.barCodes(c, t.Cancelled, color)...)
+		}
+	}
+	return cells
+}
+
+// barCodes styles a bar cell, dimmed for finished tasks and on weekends.
+func (tc textChart) barCodes(column int, muted bool, color string) []string {
+	codes := []string{color}
+	if muted || (tc.daily && !calendar.IsWorkday(tc.columns[column])) {
+		codes = append(codes, sgrDim)
+	}
+	return codes
+}
+
+// textColor returns the SGR parameters of a foreground color: the 256-color palette entry closest
+// to css, or to fallback when css is not a hex color.
+func textColor(css, fallback string) string {
+	if code, ok := ansiColor(css); ok {
+		return code
+	}
+	code, _ := ansiColor(fallback)
+	return code
+}
+
+// ansiColor maps a hex color (#rgb, #rgba, #rrggbb or #rrggbbaa) to the closest color of
+// the 6×6×6 cube of the 256-color palette.
+func ansiColor(css string) (string, bool) {
+	hex, ok := strings.CutPrefix(css, "#")
+	if !ok {
+		return "", false
+	}
+	switch len(hex) {
+	case 3, 4:
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	case 6, 8:
+		hex = hex[:6]
+	default:
+		return "", false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return "", false
+	}
+	level := func(x uint64) uint64 { return ((x&0xff)*5 + 127) / 255 }
+	return fmt.Sprintf("38;5;%d", 16+36*level(v>>16)+6*level(v>>8)+level(v)), true
+}
+
+// textPen writes ANSI escapes when color is enabled.
+type textPen struct {
+	color bool
+}
+
+// paint wraps s in the SGR codes and a reset.
+func (p textPen) paint(s string, codes ...string) string {
+	codes = slices.DeleteFunc(slices.Clone(codes), func(code string) bool { return code == "" })
+	if !p.color || len(codes) == 0 || s == "" {
+		return s
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + s + "\x1b[0m"
+}
+
+// runeCells is the number of terminal columns r takes: two for East Asian wide
+// characters, one otherwise.
+func runeCells(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && (r < 0xFF61 || r > 0xFF9F):
+		return 2
+	}
+	return 1
+}
+
+// displayWidth is the number of terminal columns s takes.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeCells(r)
+	}
+	return w
+}
+
+// visibleWidth is the number of terminal columns s takes, ANSI escapes excluded.
+func visibleWidth(s string) int {
+	w, escape := 0, false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			escape = true
+		case escape:
+			escape = r != 'm'
+		default:
+			w += runeCells(r)
+		}
+	}
+	return w
+}
+
+// truncateWidth shortens s with an ellipsis so that it takes at most width columns.
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	w := 0
+	for i, r := range s {
+		if w+runeCells(r) > width-1 {
+			return s[:i] + "…"
+		}
+		w += runeCells(r)
+	}
+	return s
+}
+
+// fitText shortens s with an ellipsis or pads it with spaces to width columns.
+func fitText(s string, width int) string {
+	s = truncateWidth(s, width)
+	return s + strings.Repeat(" ", max(width-displayWidth(s), 0))
+}
@@ -0,0 +1,126 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+func textTestTasks() []model.Task {
+	progress := 50
+	return []model.Task{
+		{Name: "設計", IsHeading: true},
+		{
+			Name:                "要件",
+			ProgressPercent:     &progress,
+			ComputedStart:       day(2024, time.June, 3),
+			ComputedEnd:         day(2024, time.June, 5),
+			ComputedActualStart: ptrTime(day(2024, time.June, 3)),
+			ComputedActualEnd:   ptrTime(day(2024, time.June, 4)),
+		},
+		{Name: "画面設計", IsHeading: true, Level: 1},
+		{Name: "画面", ComputedStart: day(2024, time.June, 10), ComputedEnd: day(2024, time.June, 12)},
+		{Name: "リリース", Milestone: true, ComputedStart: day(2024, time.June, 13), ComputedEnd: day(2024, time.June, 13)},
+	}
+}
+
+func TestBuildTextDrawsDaysAndSections(t *testing.T) {
+	out, err := BuildText(textTestTasks(), Options{Title: "計画", From: day(2024, time.June, 3), To: day(2024, time.June, 16)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "計画\n" +
+		"             │2024-06\n" +
+		"Task         │3      10\n" +
+		"設計         │     ··     ··\n" +
+		"  要件       │██▒  ··     ··\n" +
+		"             │▀▀   ··     ··\n" +
+		"  画面設計   │     ··     ··\n" +
+		"    画面     │     ··███  ··\n" +
+		"    リリース │     ··   ◆ ··\n" +
+		"\n" +
+		"█ 予定  ▒ 残り  ▀ 実績  ◆ マイルストーン  1 列 = 1 日\n"
+	if out != want {
+		t.Fatalf("unexpected text:\n%s\nwant:\n%s", out, want)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("colors written without TextColor")
+	}
+}
+
+func TestBuildTextCompressesToWeeks(t *testing.T) {
+	out, err := BuildText(textTestTasks(), Options{From: day(2024, time.June, 3), To: day(2024, time.June, 16), TextWidth: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"  要件   │█\n",
+		"    画面 │ █\n",
+		"    リ…  │ ◆\n",
+		"1 列 = 1 週（月曜始まり）\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in text:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\n")[1:5] {
+		if displayWidth(line) > 20 {
+			t.Fatalf("line wider than the terminal: %q", line)
+		}
+	}
+	if _, err := BuildText(textTestTasks(), Options{TextWidth: -1}); err == nil {
+		t.Fatalf("expected an error for a negative width")
+	}
+}
+
+func TestBuildTextColorsBarsAndMarksToday(t *testing.T) {
+	today := calendar.DateOnly(time.Now())
+	tasks := []model.Task{{Name: "作業", ComputedStart: today, ComputedEnd: today.AddDate(0, 0, 1)}}
+	out, err := BuildText(tasks, Options{From: today.AddDate(0, 0, -7), To: today.AddDate(0, 0, 7), TextColor: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, _ := ansiColor(defaultTheme.Accent)
+	marker, _ := ansiColor(defaultTheme.Today)
+	for _, want := range []string{
+		"\x1b[" + plan,
+		"\x1b[2m" + textWeekend + "\x1b[0m",
+		"\x1b[" + marker + "m" + textToday + "\x1b[0m 今日 " + formatDate(today),
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in text:\n%q", want, out)
+		}
+	}
+	if got, _ := ansiColor("#f00"); got != "38;5;196" {
+		t.Fatalf("unexpected color for red: %q", got)
+	}
+}
+
+func TestBuildTextFitsLongPlansInTheWidth(t *testing.T) {
+	tasks := append(textTestTasks(), model.Task{Name: "保守", ComputedStart: day(2025, time.January, 6), ComputedEnd: day(2027, time.May, 31)})
+	for _, tt := range []struct {
+		width int
+		unit  string
+	}{
+		{60, "1 列 = 1 か月"},
+		{30, "1 列 = 四半期"},
+		{24, "1 列 = 1 年"},
+	} {
+		opts := Options{Title: "三年計画のロードマップ", From: day(2024, time.June, 3), To: day(2027, time.June, 2), TextWidth: tt.width, TextColor: true}
+		out, err := BuildText(tasks, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(out, tt.unit) {
+			t.Fatalf("expected %q at width %d:\n%s", tt.unit, tt.width, out)
+		}
+		for _, line := range strings.Split(out, "\n") {
+			if w := visibleWidth(line); w > tt.width {
+				t.Fatalf("line of %d columns exceeds the width %d: %q", w, tt.width, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/csvinput"
+	"ganttgen/internal/model"
+)
+
+// File names of a new project directory (see Files).
+const (
+	CSVFileName      = "plan.csv"
+	HolidaysFileName = "holidays.yaml"
+	ConfigFileName   = "ganttgen.yaml"
+)
+
+// Options controls the generated template files.
+type Options struct {
+	// Lang selects header names and comments: "ja" (default) or "en".
+	Lang string
+	// Examples adds rows demonstrating sections, dependencies, actuals and progress.
+	Examples bool
+	// ExtraColumns are appended as custom columns (e.g. "assignee").
+	ExtraColumns []string
+	// Start is the project start used by the examples; zero means the next Monday.
+	Start time.Time
+}
+
+var headers = map[string][]string{
+	"ja": {"タスク名", "状態", "進捗", "開始", "終了", "期間", "依存", "実績開始", "実績終了", "実績期間", "備考"},
+	"en": {"name", "status", "progress", "start", "end", "duration", "depends_on", "actual_start", "actual_end", "actual_duration", "notes"},
+}
+
+// exampleRow is one example line; "{start}" and "{actual}" are replaced with dates. Only
+// the built-in statuses are used so that the examples pass any configured vocabulary.
+type exampleRow struct {
+	name, status, progress, start, end, duration, depends, actualStart, actualEnd, actualDuration, notes string
+}
+
+var examples = map[string][]exampleRow{
+	"ja": {
+		{name: "@project", start: "{start}", notes: "アンカー行: @project や +2w のような相対日付の基準日"},
+		{name: "#要件定義"},
+		{name: "ヒアリング", status: "完了", progress: "100%", start: "@project", duration: "3d", actualStart: "@project", actualDuration: "3d", notes: "実績は予定どおり"},
+		{name: "要件整理", progress: "60%", duration: "4d", depends: "ヒアリング", actualStart: "{actual}", notes: "依存タスクの終了翌営業日から開始"},
+		{name: "旧案の比較検討", status: "中止", start: "@project", duration: "2d", notes: "中止・完了のタスクはグレー表示"},
+		{name: "定例会（毎週月曜）", notes: "名前だけの行は表示専用"},
+		{name: "#設計"},
+		{name: "基本設計", duration: "5d", depends: "要件整理"},
+		{name: "設計レビュー", duration: "1d", depends: "基本設計"},
+		{name: "#実装"},
+		{name: "実装", duration: "10d", depends: "設計レビュー"},
+		{name: "リリース", start: "+8w", end: "+8w", notes: "+8w は @project から8週間後"},
+	},
+	"en": {
+		{name: "@project", start: "{start}", notes: "Anchor row: base date for relative dates like @project or +2w"},
+		{name: "#Requirements"},
+		{name: "Interviews", status: "completed", progress: "100%", start: "@project", duration: "3d", actualStart: "@project", actualDuration: "3d", notes: "Actuals went as planned"},
+		{name: "Requirement analysis", progress: "60%", duration: "4d", depends: "Interviews", actualStart: "{actual}", notes: "Starts the workday after its dependency ends"},
+		{name: "Old proposal review", status: "cancelled", start: "@project", duration: "2d", notes: "Cancelled and completed tasks are greyed out"},
+		{name: "Weekly meeting (Mondays)", notes: "A row with only a name is display-only"},
+		{name: "#Design"},
+		{name: "Basic design", duration: "5d", depends: "Requirement analysis"},
+		{name: "Design review", duration: "1d", depends: "Basic design"},
+		{name: "#Implementation"},
+		{name: "Implementation", duration: "10d", depends: "Design review"},
+		{name: "Release", start: "+8w", end: "+8w", notes: "+8w is eight weeks after @project"},
+	},
+}
+
+func (o Options) lang() (string, error) {
+	switch strings.ToLower(o.Lang) {
+	case "", "ja":
+		return "ja", nil
+	case "en":
+		return "en", nil
+	default:
+		return "", fmt.Errorf("unknown template language %q (use ja or en)", o.Lang)
+	}
+}
+
+func (o Options) start() time.Time {
+	if !o.Start.IsZero() {
+		return calendar.DateOnly(o.Start)
+	}
+	day := calendar.DateOnly(time.Now()).AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// CSV returns the CSV template: the header line and, with Examples, sample rows.
+func CSV(opts Options) ([]byte, error) {
+	lang, err := opts.lang()
+	if err != nil {
+		return nil, err
+	}
+	header := append(append([]string(nil), headers[lang]...), opts.ExtraColumns...)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if opts.Examples {
+		start := opts.start()
+		replacer := strings.NewReplacer(
+			"{start}", start.Format("2006-01-02"),
+			// The second example task starts right after the three-day first task.
+			"{actual}", calendar.AddWorkdays(start, 3).Format("2006-01-02"),
+		)
+		for _, ex := range examples[lang] {
+			record := []string{ex.name, ex.status, ex.progress, ex.start, ex.end, ex.duration, ex.depends, ex.actualStart, ex.actualEnd, ex.actualDuration, ex.notes}
+			for i := range record {
+				record[i] = replacer.Replace(record[i])
+			}
+			record = append(record, make([]string, len(opts.ExtraColumns))...)
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// idHeaders name the ID column that TasksCSV puts in front of the template columns.
+var idHeaders = map[string]string{"ja": "タスクID", "en": "id"}
+
+// TasksCSV writes unscheduled tasks, such as those imported from another tool, in the
+// template layout with an ID column in front. Headings become "#" rows (nested sections
+// are flattened), milestones get the duration 0d and ExtraColumns are left empty. Task
+// names that would read as a heading or an anchor are escaped (see csvinput.EscapeName).
+func TasksCSV(tasks []model.Task, opts Options) ([]byte, error) {
+	lang, err := opts.lang()
+	if err != nil {
+		return nil, err
+	}
+	header := append([]string{idHeaders[lang]}, headers[lang]...)
+	header = append(header, opts.ExtraColumns...)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		record := append(taskRecord(t), make([]string, len(opts.ExtraColumns))...)
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// taskRecord returns the ID column and the template columns of a task.
+func taskRecord(t model.Task) []string {
+	if t.IsHeading {
+		return []string{t.ID, "#" + t.Name, t.Status, "", "", "", "", "", "", "", "", t.Notes}
+	}
+	date := func(d *time.Time) string {
+		if d == nil {
+			return ""
+		}
+		return d.Format("2006-01-02")
+	}
+	days := func(n int) string {
+		if n <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%dd", n)
+	}
+	progress := ""
+	if t.ProgressPercent != nil {
+		progress = fmt.Sprintf("%d%%", *t.ProgressPercent)
+	}
+	duration := days(t.DurationDays)
+	if t.Milestone {
+		duration = "0d"
+	}
+	return []string{
+		t.ID, csvinput.EscapeName(t.Name), t.Status, progress, date(t.Start), date(t.End), duration,
+		strings.Join(t.DependsOn, ","), date(t.ActualStart), date(t.ActualEnd), days(t.ActualDurationDays), t.Notes,
+	}
+}
+
+// fixedHolidays are the Japanese national holidays on fixed dates. Holidays that move
+// every year (Coming of Age Day, equinoxes, Marine Day, ...) must be added by hand.
+var fixedHolidays = []struct {
+	month  time.Month
+	day    int
+	ja, en string
+}{
+	{time.January, 1, "元日", "New Year's Day"},
+	{time.February, 11, "建国記念の日", "National Foundation Day"},
+	{time.February, 23, "天皇誕生日", "Emperor's Birthday"},
+	{time.April, 29, "昭和の日", "Showa Day"},
+	{time.May, 3, "憲法記念日", "Constitution Memorial Day"},
+	{time.May, 4, "みどりの日", "Greenery Day"},
+	{time.May, 5, "こどもの日", "Children's Day"},
+	{time.August, 11, "山の日", "Mountain Day"},
+	{time.November, 3, "文化の日", "Culture Day"},
+	{time.November, 23, "勤労感謝の日", "Labor Thanksgiving Day"},
+}
+
+// HolidaysYAML returns a holidays file listing the fixed-date holidays of the project
+// year and the year after.
+func HolidaysYAML(opts Options) ([]byte, error) {
+	lang, err := opts.lang()
+	if err != nil {
+		return nil, err
+	}
+	year := opts.start().Year()
+	var b strings.Builder
+	if lang == "ja" {
+		b.WriteString("# 非稼働日の一覧（YYYY-MM-DD）。\n")
+		b.WriteString("# 日付が固定の祝日のみ記載しています。成人の日・春分の日などの移動祝日や会社の休日は追記してください。\n")
+	} else {
+		b.WriteString("# Non-working days (YYYY-MM-DD).\n")
+		b.WriteString("# Only fixed-date Japanese holidays are listed; add moving holidays (equinoxes, Happy Mondays) and company holidays.\n")
+	}
+	b.WriteString("holidays:\n")
+	for _, y := range []int{year, year + 1} {
+		for _, h := range fixedHolidays {
+			name := h.ja
+			if lang == "en" {
+				name = h.en
+			}
+			fmt.Fprintf(&b, "  - %04d-%02d-%02d # %s\n", y, h.month, h.day, name)
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// ConfigYAML returns a ganttgen.yaml referring to the other scaffold files.
+func ConfigYAML(opts Options) ([]byte, error) {
+	lang, err := opts.lang()
+	if err != nil {
+		return nil, err
+	}
+	title := "ガントチャート"
+	comments := []string{
+		"# ganttgen の設定ファイル。コマンドラインのフラグが優先されます。",
+		"# 相対パスはこのファイルのディレクトリが基準です。",
+	}
+	themeComment := "# theme:          # 色の変更"
+	if lang == "en" {
+		title = "Gantt Chart"
+		comments = []string{
+			"# ganttgen project configuration. Command-line flags take precedence.",
+			"# Relative paths are resolved against this file's directory.",
+		}
+		themeComment = "# theme:          # change colors"
+	}
+	var b strings.Builder
+	for _, c := range comments {
+		b.WriteString(c + "\n")
+	}
+	fmt.Fprintf(&b, "output: gantt.html\n")
+	fmt.Fprintf(&b, "holidays: [%s]\n", HolidaysFileName)
+	b.WriteString("calendar:\n  weekends: [sat, sun]\n")
+	b.WriteString("input:\n  encoding: auto\n  delimiter: auto\n")
+	fmt.Fprintf(&b, "render:\n  title: %s\n", title)
+	b.WriteString(themeComment + "\n")
+	b.WriteString("#   accent: \"#4c6fff\"\n")
+	b.WriteString("#   actual: \"#f97316\"\n")
+	b.WriteString("livereload:\n  port: 35729\n")
+	return []byte(b.String()), nil
+}
+
+// Files returns the files of a new project directory keyed by file name.
+func Files(opts Options) (map[string][]byte, error) {
+	csvData, err := CSV(opts)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := HolidaysYAML(opts)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ConfigYAML(opts)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		CSVFileName:      csvData,
+		HolidaysFileName: holidays,
+		ConfigFileName:   cfg,
+	}, nil
+}
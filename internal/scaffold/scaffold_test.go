@@ -0,0 +1,171 @@
+package scaffold
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/config"
+	"ganttgen/internal/csvinput"
+	"ganttgen/internal/model"
+	"ganttgen/internal/scheduler"
+)
+
+func TestCSVHeaderOnly(t *testing.T) {
+	data, err := CSV(Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "タスク名,状態,進捗,開始,終了,期間,依存,実績開始,実績終了,実績期間,備考\n"
+	if string(data) != want {
+		t.Fatalf("unexpected header:\n%s", data)
+	}
+}
+
+func TestCSVExamplesSchedule(t *testing.T) {
+	for _, lang := range []string{"ja", "en"} {
+		data, err := CSV(Options{Lang: lang, Examples: true, ExtraColumns: []string{"assignee"}, Start: time.Date(2026, time.April, 6, 0, 0, 0, 0, time.Local)})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", lang, err)
+		}
+		tasks, customColumns, hasProgress, err := csvinput.ReadFrom(bytes.NewReader(data), csvinput.Options{})
+		if err != nil {
+			t.Fatalf("%s: example CSV does not parse: %v\n%s", lang, err, data)
+		}
+		if len(customColumns) != 1 || customColumns[0] != "assignee" || !hasProgress {
+			t.Fatalf("%s: unexpected columns %v (progress %v)", lang, customColumns, hasProgress)
+		}
+		scheduled, err := scheduler.Schedule(tasks)
+		if err != nil {
+			t.Fatalf("%s: example CSV does not schedule: %v", lang, err)
+		}
+		for _, task := range scheduled {
+			if task.HasActual() && task.ComputedActualStart.Before(time.Date(2026, time.April, 6, 0, 0, 0, 0, time.Local)) {
+				t.Fatalf("%s: actual before project start: %+v", lang, task)
+			}
+		}
+	}
+}
+
+func TestCSVExamplesPassAConfiguredVocabulary(t *testing.T) {
+	if err := model.SetStatuses([]model.StatusDef{{Name: "作業中", Semantic: model.SemanticInProgress}}); err != nil {
+		t.Fatalf("set statuses: %v", err)
+	}
+	t.Cleanup(func() { model.SetStatuses(nil) })
+	for _, lang := range []string{"ja", "en"} {
+		data, err := CSV(Options{Lang: lang, Examples: true})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", lang, err)
+		}
+		if _, _, _, err := csvinput.ReadFrom(bytes.NewReader(data), csvinput.Options{}); err != nil {
+			t.Fatalf("%s: example CSV does not validate: %v\n%s", lang, err, data)
+		}
+	}
+}
+
+func TestCSVRejectsUnknownLanguage(t *testing.T) {
+	if _, err := CSV(Options{Lang: "fr"}); err == nil {
+		t.Fatalf("expected error for unknown language")
+	}
+}
+
+func TestFilesFormAProject(t *testing.T) {
+	files, err := Files(Options{Lang: "en", Examples: true, Start: time.Date(2026, time.April, 6, 0, 0, 0, 0, time.Local)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := config.Load(filepath.Join(dir, ConfigFileName))
+	if err != nil {
+		t.Fatalf("config does not load: %v", err)
+	}
+	if len(cfg.Holidays) != 1 || cfg.Holidays[0] != filepath.Join(dir, HolidaysFileName) {
+		t.Fatalf("config does not refer to the holidays file: %v", cfg.Holidays)
+	}
+	dates, err := calendar.ReadHolidaysYAML(cfg.Holidays[0])
+	if err != nil {
+		t.Fatalf("holidays do not load: %v", err)
+	}
+	if len(dates) == 0 || dates[0].Format("2006-01-02") != "2026-01-01" {
+		t.Fatalf("unexpected holidays: %v", dates)
+	}
+	if !strings.Contains(string(files[CSVFileName]), "#Design") {
+		t.Fatalf("expected example rows in the CSV")
+	}
+}
+
+func TestTasksCSVReadsBack(t *testing.T) {
+	start := time.Date(2099, time.June, 1, 0, 0, 0, 0, time.Local)
+	progress := 40
+	tasks := []model.Task{
+		{Name: "設計", IsHeading: true},
+		{ID: "1", Name: "要件, 確認", Start: &start, DurationDays: 3, ProgressPercent: &progress, ActualStart: &start, Notes: "メモ"},
+		{ID: "2", Name: "レビュー", Milestone: true, DependsOn: []string{"1"}},
+		{ID: "3", Name: "実装", Status: "中止", DurationDays: 2, DependsOn: []string{"1", "2"}},
+	}
+	data, err := TasksCSV(tasks, Options{ExtraColumns: []string{"担当"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "タスクID,タスク名,状態,進捗,開始,終了,期間,依存,実績開始,実績終了,実績期間,備考,担当\n,#設計,") {
+		t.Fatalf("unexpected CSV:\n%s", data)
+	}
+	read, _, hasProgress, err := csvinput.ReadFrom(bytes.NewReader(data), csvinput.Options{})
+	if err != nil {
+		t.Fatalf("CSV does not parse: %v\n%s", err, data)
+	}
+	scheduled, err := scheduler.Schedule(read)
+	if err != nil {
+		t.Fatalf("CSV does not schedule: %v", err)
+	}
+	if !hasProgress || len(scheduled) != 4 || !scheduled[0].IsHeading {
+		t.Fatalf("unexpected tasks: %+v", scheduled)
+	}
+	want := map[string]string{"1": "2099-06-03", "2": "2099-06-03", "3": "2099-06-05"}
+	for _, task := range scheduled[1:] {
+		if got := task.ComputedEnd.Format("2006-01-02"); got != want[task.ID] {
+			t.Fatalf("task %s ends %s, want %s", task.ID, got, want[task.ID])
+		}
+	}
+	if !scheduled[2].Milestone || !scheduled[3].IsCancelled() || scheduled[1].Name != "要件, 確認" {
+		t.Fatalf("unexpected tasks: %+v", scheduled)
+	}
+}
+
+func TestTasksCSVEscapesHeadingAndAnchorNames(t *testing.T) {
+	start := time.Date(2099, time.June, 1, 0, 0, 0, 0, time.Local)
+	tasks := []model.Task{
+		{ID: "1", Name: "#1 Kickoff", Start: &start, DurationDays: 1},
+		{ID: "2", Name: "@review", DurationDays: 2, DependsOn: []string{"1"}},
+		{ID: "3", Name: `\share`, DurationDays: 1, DependsOn: []string{"2"}},
+	}
+	data, err := TasksCSV(tasks, Options{Lang: "en"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	read, _, _, err := csvinput.ReadFrom(bytes.NewReader(data), csvinput.Options{})
+	if err != nil {
+		t.Fatalf("CSV does not parse: %v\n%s", err, data)
+	}
+	if len(read) != 3 {
+		t.Fatalf("unexpected tasks: %+v", read)
+	}
+	for i, task := range read {
+		if task.IsHeading || task.Name != tasks[i].Name {
+			t.Fatalf("task %d read back as %q (heading %v), want %q\n%s", i, task.Name, task.IsHeading, tasks[i].Name, data)
+		}
+	}
+	if _, err := scheduler.Schedule(read); err != nil {
+		t.Fatalf("CSV does not schedule: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"time"
+
+	"ganttgen/internal/calendar"
+	"ganttgen/internal/model"
+)
+
+// Float returns the total float of scheduled tasks keyed by task key: the number of
+// workdays a task can finish later without delaying a dependent task or the end of the
+// project. Tasks on the critical path have zero float. Headings, display-only rows and
+// cancelled tasks are left out and do not hold up other tasks.
+func Float(tasks []model.Task) map[string]int {
+	var (
+		active     []model.Task
+		projectEnd time.Time
+	)
+	for _, t := range tasks {
+		if t.IsHeading || t.DisplayOnly || t.IsCancelled() {
+			continue
+		}
+		active = append(active, t)
+		if t.ComputedEnd.After(projectEnd) {
+			projectEnd = t.ComputedEnd
+		}
+	}
+	byKey := make(map[string]model.Task, len(active))
+	successors := make(map[string][]string, len(active))
+	for _, t := range active {
+		byKey[t.Key()] = t
+		for _, dep := range t.DependsOn {
+			successors[dep] = append(successors[dep], t.Key())
+		}
+	}
+
+	// lateFinish is the latest end of a task that keeps its successors and the project end.
+	// Schedule has rejected cycles, so the recursion ends.
+	lateFinish := make(map[string]time.Time, len(active))
+	var finish func(key string) time.Time
+	finish = func(key string) time.Time {
+		if late, ok := lateFinish[key]; ok {
+			return late
+		}
+		late := projectEnd
+		for _, next := range successors[key] {
+			s := byKey[next]
+			// The successor may start as late as its float allows, keeping its length.
+			start := finish(next)
+			for i := calendar.CountWorkdays(s.ComputedStart, s.ComputedEnd); i > 1; i-- {
+				start = calendar.PreviousWorkdayBefore(start)
+			}
+			if !s.Milestone {
+				// Other tasks start the workday after their dependencies end.
+				start = calendar.PreviousWorkdayBefore(start)
+			}
+			if start.Before(late) {
+				late = start
+			}
+		}
+		lateFinish[key] = late
+		return late
+	}
+
+	floats := make(map[string]int, len(active))
+	for _, t := range active {
+		floats[t.Key()] = calendar.WorkdaysBetween(t.ComputedEnd, finish(t.Key()))
+	}
+	return floats
+}
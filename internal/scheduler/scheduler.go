@@ -16,13 +16,13 @@ func Schedule(tasks []model.Task) ([]model.Task, error) {
 		return nil, errors.New("no tasks to schedule")
 	}
 
-	byName := make(map[string]model.Task, len(tasks))
+	byKey := make(map[string]model.Task, len(tasks))
 	for i := range tasks {
 		task := tasks[i]
 		if task.IsHeading {
 			continue
 		}
-		byName[task.Name] = task
+		byKey[task.Key()] = task
 	}
 
 	indegree := make(map[string]int, len(tasks))
@@ -33,16 +33,16 @@ func Schedule(tasks []model.Task) ([]model.Task, error) {
 			continue
 		}
 		schedulableCount++
-		indegree[t.Name] = len(t.DependsOn)
+		indegree[t.Key()] = len(t.DependsOn)
 		for _, dep := range t.DependsOn {
-			graph[dep] = append(graph[dep], t.Name)
+			graph[dep] = append(graph[dep], t.Key())
 		}
 	}
 
 	queue := make([]string, 0, len(tasks))
-	for name, deg := range indegree {
+	for key, deg := range indegree {
 		if deg == 0 {
-			queue = append(queue, name)
+			queue = append(queue, key)
 		}
 	}
 	sort.Strings(queue) // deterministic start order
@@ -51,21 +51,21 @@ func Schedule(tasks []model.Task) ([]model.Task, error) {
 	scheduledCount := 0
 
 	for len(queue) > 0 {
-		name := queue[0]
+		key := queue[0]
 		queue = queue[1:]
 
-		taskVal, ok := byName[name]
+		taskVal, ok := byKey[key]
 		if !ok {
-			return nil, fmt.Errorf("unknown task referenced in queue: %s", name)
+			return nil, fmt.Errorf("unknown task referenced in queue: %s", key)
 		}
 		scheduledTask, err := computeSchedule(taskVal, scheduled)
 		if err != nil {
 			return nil, err
 		}
-		scheduled[name] = scheduledTask
+		scheduled[key] = scheduledTask
 		scheduledCount++
 
-		for _, successor := range graph[name] {
+		for _, successor := range graph[key] {
 			indegree[successor]--
 			if indegree[successor] == 0 {
 				queue = append(queue, successor)
@@ -84,9 +84,9 @@ func Schedule(tasks []model.Task) ([]model.Task, error) {
 			ordered = append(ordered, t)
 			continue
 		}
-		scheduledTask, ok := scheduled[t.Name]
+		scheduledTask, ok := scheduled[t.Key()]
 		if !ok {
-			return nil, fmt.Errorf("task %q could not be scheduled", t.Name)
+			return nil, fmt.Errorf("task %q could not be scheduled", t.Key())
 		}
 		ordered = append(ordered, scheduledTask)
 	}
@@ -112,7 +112,7 @@ func computeSchedule(task model.Task, scheduled map[string]model.Task) (model.Ta
 		for _, dep := range task.DependsOn {
 			depTask, ok := scheduled[dep]
 			if !ok {
-				return model.Task{}, fmt.Errorf("dependency %q for task %q not scheduled", dep, task.Name)
+				return model.Task{}, fmt.Errorf("dependency %q for task %q not scheduled", dep, task.Key())
 			}
 			if !seen || depTask.ComputedEnd.After(latestEnd.Time) {
 				latestEnd = modelTaskDate{depTask.ComputedEnd}
@@ -120,6 +120,10 @@ func computeSchedule(task model.Task, scheduled map[string]model.Task) (model.Ta
 			}
 		}
 		depStart := modelTaskDate{calendar.NextWorkdayAfter(latestEnd.Time)}
+		if task.Milestone {
+			// A milestone marks the day its dependencies are finished.
+			depStart = latestEnd
+		}
 		if !hasStart || depStart.After(start.Time) {
 			start = depStart
 			hasStart = true
@@ -127,26 +131,93 @@ func computeSchedule(task model.Task, scheduled map[string]model.Task) (model.Ta
 	}
 
 	if !hasStart {
-		return model.Task{}, fmt.Errorf("task %q lacks a resolvable start date", task.Name)
+		return model.Task{}, fmt.Errorf("task %q lacks a resolvable start date", task.Key())
+	}
+	if len(task.Pauses) > 0 {
+		start = modelTaskDate{skipPauses(start.Time, task.Pauses)}
 	}
 
 	var end modelTaskDate
 	if task.End != nil {
 		end = modelTaskDate{calendar.NextWorkday(*task.End)}
 		if end.Before(start.Time) {
-			return model.Task{}, fmt.Errorf("task %q ends before it can start", task.Name)
+			return model.Task{}, fmt.Errorf("task %q ends before it can start", task.Key())
 		}
+	} else if task.DurationDays > 0 && len(task.Pauses) > 0 {
+		end = modelTaskDate{addWorkdaysSkipping(start.Time, task.DurationDays-1, task.Pauses)}
 	} else if task.DurationDays > 0 {
 		end = modelTaskDate{calendar.AddWorkdays(start.Time, task.DurationDays-1)}
+	} else if task.Milestone {
+		end = start
 	} else {
-		return model.Task{}, fmt.Errorf("task %q lacks duration or end", task.Name)
+		return model.Task{}, fmt.Errorf("task %q lacks duration or end", task.Key())
 	}
 
 	task.ComputedStart = start.Time
 	task.ComputedEnd = end.Time
+	task.ComputedSegments = nil
+	if len(task.Pauses) > 0 {
+		segments := splitByPauses(start.Time, end.Time, task.Pauses)
+		if len(segments) == 0 {
+			return model.Task{}, fmt.Errorf("task %q is paused for its whole schedule", task.Key())
+		}
+		if len(segments) > 1 {
+			task.ComputedSegments = segments
+		}
+	}
 	return task, nil
 }
 
+func paused(day time.Time, pauses []model.Interval) bool {
+	for _, p := range pauses {
+		if p.Contains(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipPauses moves day to the first workday that is not paused.
+func skipPauses(day time.Time, pauses []model.Interval) time.Time {
+	day = calendar.NextWorkday(day)
+	for paused(day, pauses) {
+		day = calendar.NextWorkdayAfter(day)
+	}
+	return day
+}
+
+// addWorkdaysSkipping is calendar.AddWorkdays where paused days do not count.
+func addWorkdaysSkipping(start time.Time, days int, pauses []model.Interval) time.Time {
+	current := skipPauses(start, pauses)
+	for i := 0; i < days; i++ {
+		current = skipPauses(current.AddDate(0, 0, 1), pauses)
+	}
+	return current
+}
+
+// splitByPauses returns the runs of unpaused days between start and end, each trimmed to
+// begin and end on a workday. Non-working days inside a run do not split it.
+func splitByPauses(start, end time.Time, pauses []model.Interval) []model.Interval {
+	var segments []model.Interval
+	var current *model.Interval
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if paused(day, pauses) {
+			current = nil
+			continue
+		}
+		if !calendar.IsWorkday(day) {
+			continue
+		}
+		if current == nil {
+			segments = append(segments, model.Interval{Start: day, End: day})
+			current = &segments[len(segments)-1]
+			continue
+		}
+		current.End = day
+	}
+	return segments
+}
+
 type modelTaskDate struct {
 	time.Time
 }
@@ -61,6 +61,72 @@ func TestScheduleDetectsCycle(t *testing.T) {
 	}
 }
 
+func TestScheduleUsesIDsForDependencies(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "a", Name: "Implement", Start: ptrTime(d(2024, time.June, 3)), DurationDays: 1},
+		{ID: "b", Name: "Implement", DependsOn: []string{"a"}, DurationDays: 1},
+	}
+	got, err := Schedule(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got[1].ComputedStart.Equal(d(2024, time.June, 4)) {
+		t.Fatalf("dependent start mismatch: %v", got[1].ComputedStart)
+	}
+}
+
+func TestScheduleMilestoneTakesOneDay(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "build", Name: "Build", Start: ptrTime(d(2024, time.June, 5)), DurationDays: 3},
+		{ID: "release", Name: "Release", DependsOn: []string{"build"}, Milestone: true},
+	}
+	got, err := Schedule(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The milestone falls on the day Build ends, not on the next workday.
+	release := got[1]
+	if !release.ComputedStart.Equal(d(2024, time.June, 7)) || !release.ComputedEnd.Equal(release.ComputedStart) {
+		t.Fatalf("unexpected milestone dates: %v - %v", release.ComputedStart, release.ComputedEnd)
+	}
+}
+
+func TestSchedulePausesExtendDurationAndSplitSegments(t *testing.T) {
+	tasks := []model.Task{
+		{
+			Name:         "Build",
+			Start:        ptrTime(d(2026, time.January, 26)),
+			DurationDays: 10,
+			Pauses:       []model.Interval{{Start: d(2026, time.February, 2), End: d(2026, time.February, 13)}},
+		},
+		{Name: "Review", DependsOn: []string{"Build"}, DurationDays: 1},
+	}
+
+	got, err := Schedule(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	build := findTask(t, got, "Build")
+	if !build.ComputedEnd.Equal(d(2026, time.February, 20)) {
+		t.Fatalf("build end mismatch: %v", build.ComputedEnd)
+	}
+	want := []model.Interval{
+		{Start: d(2026, time.January, 26), End: d(2026, time.January, 30)},
+		{Start: d(2026, time.February, 16), End: d(2026, time.February, 20)},
+	}
+	if len(build.ComputedSegments) != len(want) {
+		t.Fatalf("expected %d segments, got %#v", len(want), build.ComputedSegments)
+	}
+	for i, seg := range build.ComputedSegments {
+		if !seg.Start.Equal(want[i].Start) || !seg.End.Equal(want[i].End) {
+			t.Fatalf("segment %d mismatch: %v - %v", i, seg.Start, seg.End)
+		}
+	}
+	if review := findTask(t, got, "Review"); !review.ComputedStart.Equal(d(2026, time.February, 23)) {
+		t.Fatalf("review start mismatch: %v", review.ComputedStart)
+	}
+}
+
 func ptrTime(t time.Time) *time.Time { return &t }
 
 func findTask(t *testing.T, tasks []model.Task, name string) model.Task {
@@ -73,3 +139,29 @@ func findTask(t *testing.T, tasks []model.Task, name string) model.Task {
 	t.Fatalf("task %s not found", name)
 	return model.Task{}
 }
+
+func TestFloatMeasuresSlackToSuccessorsAndProjectEnd(t *testing.T) {
+	tasks := []model.Task{
+		{Name: "Section", IsHeading: true},
+		{Name: "A", Start: ptrTime(d(2024, time.June, 3)), DurationDays: 3},
+		{Name: "B", Start: ptrTime(d(2024, time.June, 3)), DurationDays: 1},
+		{Name: "C", DependsOn: []string{"A", "B"}, DurationDays: 2},
+		{Name: "D", DependsOn: []string{"C"}, Milestone: true},
+		{Name: "E", Start: ptrTime(d(2024, time.June, 3)), DurationDays: 10},
+		{Name: "X", Status: "中止", Start: ptrTime(d(2024, time.June, 3)), DurationDays: 30},
+	}
+	got, err := Schedule(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	floats := Float(got)
+	want := map[string]int{"A": 5, "B": 7, "C": 5, "D": 5, "E": 0}
+	if len(floats) != len(want) {
+		t.Fatalf("unexpected floats: %v", floats)
+	}
+	for key, days := range want {
+		if floats[key] != days {
+			t.Fatalf("float of %s = %d, want %d (all: %v)", key, floats[key], days, floats)
+		}
+	}
+}